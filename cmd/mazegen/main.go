@@ -0,0 +1,309 @@
+// Command mazegen generates, solves, measures, renders and validates mazes
+// from the command line, without opening a window. Mazes are read and
+// written in the text format of Maze.String, with the start and exit
+// markers of Maze.MarkedString, which only describes square grids
+//
+// Usage:
+//
+//	mazegen generate [-width 20] [-height 15] [-seed n] [-algorithm name] [-loops 0] [-plain] [-o file]
+//	mazegen solve [file]
+//	mazegen stats [file]
+//	mazegen render [-format ascii|plain|box|svg|png] [-cell 20] [-solution] [-o file] [file]
+//	mazegen validate [-lenient] [file]
+//
+// Commands that take a file read standard input without one
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bfreis/trijam-304/game"
+)
+
+const usage = `usage: mazegen <command> [flags] [file]
+
+Commands:
+  generate  generate a maze and write it out
+  solve     print the shortest way out from the start
+  stats     print how hard the maze is
+  render    draw the maze as text, SVG or PNG
+  validate  check that the maze is well formed and can be solved
+
+Run "mazegen <command> -h" for the flags of a command
+`
+
+// errUsage reports a command line that could not be understood, after the
+// usage has been printed
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command line and returns the exit status
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	commands := map[string]func([]string, io.Reader, io.Writer, io.Writer) error{
+		"generate": generate,
+		"solve":    solve,
+		"stats":    stats,
+		"render":   render,
+		"validate": validate,
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "mazegen: unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+	err := command(args[1:], stdin, stdout, stderr)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	default:
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(stderr, "mazegen: %s\n", line)
+		}
+		return 1
+	}
+}
+
+// newFlagSet returns a flag set for the command that reports errors to
+// stderr instead of exiting
+func newFlagSet(name, arguments string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: mazegen %s [flags] %s\n", name, arguments)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags parses the command's flags, turning parse errors into errUsage
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// readMaze reads the maze named by the only argument left after the flags,
+// or from stdin if there is none
+func readMaze(flags *flag.FlagSet, stdin io.Reader, options game.ParseOptions) (*game.Maze, error) {
+	var name string
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		name = flags.Arg(0)
+	default:
+		flags.Usage()
+		return nil, errUsage
+	}
+
+	var data []byte
+	var err error
+	if name == "" || name == "-" {
+		name = "standard input"
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	maze, err := options.Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return maze, nil
+}
+
+// writeOutput writes the data produced by write to the named file, or to
+// stdout if there is no name
+func writeOutput(name string, stdout io.Writer, write func(io.Writer) error) error {
+	if name == "" {
+		return write(stdout)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func generate(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := newFlagSet("generate", "", stderr)
+	width := flags.Int("width", 20, "number of columns")
+	height := flags.Int("height", 15, "number of rows")
+	seed := flags.Int64("seed", 0, "seed of the maze; zero picks one at random")
+	algorithm := flags.String("algorithm", game.AlgorithmBacktracker.String(), "algorithm carving the passages")
+	loops := flags.Float64("loops", 0, "fraction of dead ends, between 0 and 1, to turn into loops")
+	plain := flags.Bool("plain", false, "leave out the start and exit markers")
+	output := flags.String("o", "", "file to write the maze to, instead of standard output")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	a, ok := game.MazeAlgorithmByName(*algorithm)
+	if !ok {
+		return fmt.Errorf("unknown algorithm %q", *algorithm)
+	}
+	if *width < 1 || *height < 1 {
+		return fmt.Errorf("invalid size %dx%d", *width, *height)
+	}
+	if *loops < 0 || *loops > 1 {
+		return fmt.Errorf("invalid loops %v, want between 0 and 1", *loops)
+	}
+	if *seed == 0 {
+		*seed = game.NewSeed()
+	}
+
+	maze, _ := game.MazeConfig{
+		Width:     *width,
+		Height:    *height,
+		Seed:      *seed,
+		Generator: a.Generator(),
+		Braid:     *loops,
+	}.Generate()
+	fmt.Fprintf(stderr, "seed %d\n", *seed)
+	return writeOutput(*output, stdout, func(w io.Writer) error {
+		text := maze.MarkedString()
+		if *plain {
+			text = maze.String()
+		}
+		_, err := io.WriteString(w, text)
+		return err
+	})
+}
+
+func solve(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := newFlagSet("solve", "[file]", stderr)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	maze, err := readMaze(flags, stdin, game.ParseOptions{Lenient: true})
+	if err != nil {
+		return err
+	}
+
+	path, ok := maze.ShortestPath(maze.Start)
+	if !ok {
+		return fmt.Errorf("no way out from (%d, %d)", maze.Start.X, maze.Start.Y)
+	}
+	directions := make([]string, len(path.Directions))
+	for i, dir := range path.Directions {
+		directions[i] = dir.String()
+	}
+	fmt.Fprintf(stdout, "%d moves from (%d, %d)\n", path.Len(), maze.Start.X, maze.Start.Y)
+	fmt.Fprintln(stdout, strings.Join(directions, " "))
+	return nil
+}
+
+func stats(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := newFlagSet("stats", "[file]", stderr)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	maze, err := readMaze(flags, stdin, game.ParseOptions{Lenient: true})
+	if err != nil {
+		return err
+	}
+
+	metrics := maze.Metrics(maze.Start)
+	validation := maze.Validate(maze.Start)
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "size\t%dx%d\n", maze.Width, maze.Height)
+	fmt.Fprintf(w, "cells\t%d\n", metrics.Cells)
+	fmt.Fprintf(w, "dead ends\t%d\n", metrics.DeadEnds)
+	if metrics.SolutionLength > 0 {
+		fmt.Fprintf(w, "solution\t%d moves, %d turns\n", metrics.SolutionLength, metrics.SolutionTurns)
+	} else {
+		fmt.Fprintf(w, "solution\tnone\n")
+	}
+	fmt.Fprintf(w, "branching\t%.2f\n", metrics.BranchingFactor)
+	fmt.Fprintf(w, "off solution\t%.0f%%\n", metrics.OffSolutionShare*100)
+	fmt.Fprintf(w, "difficulty\t%.2f\n", metrics.Score())
+	fmt.Fprintf(w, "perfect\t%t\n", validation.Perfect)
+	return w.Flush()
+}
+
+func render(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := newFlagSet("render", "[file]", stderr)
+	format := flags.String("format", "ascii", "output format: ascii, plain, box, svg or png")
+	cellSize := flags.Float64("cell", 20, "size of a cell in pixels, for svg and png")
+	solution := flags.Bool("solution", false, "draw the shortest way out, for svg and png")
+	output := flags.String("o", "", "file to write the drawing to, instead of standard output")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	maze, err := readMaze(flags, stdin, game.ParseOptions{Lenient: true})
+	if err != nil {
+		return err
+	}
+
+	options := game.ExportOptions{CellSize: *cellSize, ShowStart: true, ShowExit: true, ShowSolution: *solution}
+	var write func(io.Writer) error
+	switch *format {
+	case "ascii", "plain", "box":
+		text := map[string]func() string{"ascii": maze.MarkedString, "plain": maze.String, "box": maze.BoxString}[*format]
+		write = func(w io.Writer) error {
+			_, err := io.WriteString(w, text())
+			return err
+		}
+	case "svg":
+		write = func(w io.Writer) error { return maze.WriteSVG(w, options) }
+	case "png":
+		write = func(w io.Writer) error { return maze.WritePNG(w, options) }
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	return writeOutput(*output, stdout, write)
+}
+
+func validate(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := newFlagSet("validate", "[file]", stderr)
+	lenient := flags.Bool("lenient", false, "accept CRLF line endings and trailing whitespace")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	maze, err := readMaze(flags, stdin, game.ParseOptions{Strict: true, Lenient: *lenient})
+	if err != nil {
+		return err
+	}
+
+	validation := maze.Validate(maze.Start)
+	if err := validation.Err(); err != nil {
+		return err
+	}
+	if validation.Perfect {
+		fmt.Fprintln(stdout, "ok, perfect maze")
+	} else {
+		fmt.Fprintln(stdout, "ok, maze with loops")
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bfreis/trijam-304/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runCommand runs the command line with the given stdin and returns the
+// exit status and what was written to stdout and stderr
+func runCommand(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestGenerate(t *testing.T) {
+	status, out, errOut := runCommand(t, "", "generate", "-width", "6", "-height", "4", "-seed", "9", "-algorithm", "prim")
+	require.Equal(t, 0, status, errOut)
+	assert.Equal(t, "seed 9\n", errOut)
+
+	maze, err := game.ParseMaze(out)
+	require.NoError(t, err)
+	want, _ := game.MazeConfig{Width: 6, Height: 4, Seed: 9, Generator: game.PrimGenerator{}}.Generate()
+	assert.Equal(t, want.MarkedString(), maze.MarkedString())
+
+	_, plain, _ := runCommand(t, "", "generate", "-width", "6", "-height", "4", "-seed", "9", "-algorithm", "prim", "-plain")
+	assert.Equal(t, want.String(), plain)
+
+	status, _, errOut = runCommand(t, "", "generate", "-algorithm", "bogosort")
+	assert.Equal(t, 1, status)
+	assert.Contains(t, errOut, `unknown algorithm "bogosort"`)
+	status, _, _ = runCommand(t, "", "generate", "-width", "x")
+	assert.Equal(t, 2, status)
+}
+
+func TestSolveAndStats(t *testing.T) {
+	const maze = `
++--+--+--+
+|S       |
++  +--+  +
+|  |  |
++--+--+--+`
+
+	status, out, errOut := runCommand(t, maze, "solve")
+	require.Equal(t, 0, status, errOut)
+	assert.Equal(t, "4 moves from (0, 0)\nEast East South East\n", out)
+
+	status, out, errOut = runCommand(t, maze, "stats")
+	require.Equal(t, 0, status, errOut)
+	assert.Contains(t, out, "size          3x2\n")
+	assert.Contains(t, out, "solution      4 moves, 2 turns\n")
+	assert.Contains(t, out, "perfect       false\n")
+
+	status, _, errOut = runCommand(t, "+--+\n|S |\n+--+", "solve")
+	assert.Equal(t, 1, status)
+	assert.Contains(t, errOut, "no way out")
+}
+
+func TestRender(t *testing.T) {
+	_, maze, _ := runCommand(t, "", "generate", "-width", "5", "-height", "5", "-seed", "3")
+
+	status, out, _ := runCommand(t, maze, "render")
+	require.Equal(t, 0, status)
+	assert.Equal(t, maze, out)
+
+	status, out, _ = runCommand(t, maze, "render", "-format", "box")
+	require.Equal(t, 0, status)
+	assert.Contains(t, out, "┌")
+
+	status, out, _ = runCommand(t, maze, "render", "-format", "svg", "-solution")
+	require.Equal(t, 0, status)
+	assert.True(t, strings.HasPrefix(out, "<svg"))
+
+	file := filepath.Join(t.TempDir(), "maze.png")
+	status, out, _ = runCommand(t, maze, "render", "-format", "png", "-cell", "10", "-o", file)
+	require.Equal(t, 0, status)
+	assert.Empty(t, out)
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	img, err := png.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, 60, img.Bounds().Dx())
+
+	status, _, errOut := runCommand(t, maze, "render", "-format", "gif")
+	assert.Equal(t, 1, status)
+	assert.Contains(t, errOut, `unknown format "gif"`)
+}
+
+func TestValidate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "maze.txt")
+	require.NoError(t, os.WriteFile(file, []byte("+--+--+\n|S    |\n+--+  +\n"), 0o644))
+	status, out, errOut := runCommand(t, "", "validate", file)
+	assert.Equal(t, 0, status, errOut)
+	assert.Equal(t, "ok, perfect maze\n", out)
+
+	status, _, errOut = runCommand(t, "+--+--+\n|S |  |\n+--+  +\n", "validate")
+	assert.Equal(t, 1, status)
+	assert.Contains(t, errOut, "mazegen: 1 cells cannot be reached from the start")
+
+	status, _, errOut = runCommand(t, "+--+--+\n|S    |\n+--+ -+\n", "validate")
+	assert.Equal(t, 1, status)
+	assert.Contains(t, errOut, "mazegen: standard input: line 3, column 5")
+
+	status, _, _ = runCommand(t, "+--+--+\r\n|S    |\r\n+--+  +\r\n", "validate", "-lenient")
+	assert.Equal(t, 0, status)
+}
+
+func TestUsage(t *testing.T) {
+	status, _, errOut := runCommand(t, "")
+	assert.Equal(t, 2, status)
+	assert.Contains(t, errOut, "usage: mazegen")
+
+	status, _, errOut = runCommand(t, "", "frobnicate")
+	assert.Equal(t, 2, status)
+	assert.Contains(t, errOut, `unknown command "frobnicate"`)
+}
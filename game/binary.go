@@ -0,0 +1,350 @@
+package game
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// The binary encoding of a maze is, in order:
+//
+//   - the magic bytes "MAZE" and a version byte, currently 2
+//   - a flags byte saying which of the optional parts below are present
+//   - width and height as uvarints, and the seed as a varint
+//   - the generator and topology names, each as a uvarint length followed
+//     by the bytes of the name
+//   - the start position, two uvarints
+//   - the exit, if present: its position as two uvarints and its direction
+//     as one byte
+//   - the goal, if present: its position as two uvarints
+//   - the mask, if present: one bit per cell, row by row, set where the
+//     cell is enabled
+//   - the walls, one bit per wall set where there is a wall, in the order
+//     of Maze.wallIndexes: only the walls the cells of the maze have, each
+//     shared wall once
+//   - the crossings, if present: two bits per cell, row by row
+//   - a CRC-32 (IEEE) of everything before it, big endian
+//
+// Bits are packed into bytes starting from the lowest bit. Any change to
+// this layout needs a new version
+const (
+	binaryMagic   = "MAZE"
+	binaryVersion = 2
+)
+
+const (
+	binaryHasExit = 1 << iota
+	binaryHasGoal
+	binaryHasMask
+	binaryHasCrossings
+
+	binaryKnownFlags = binaryHasExit | binaryHasGoal | binaryHasMask | binaryHasCrossings
+)
+
+var (
+	// ErrNotMaze is returned when decoding data that does not start like
+	// an encoded maze
+	ErrNotMaze = errors.New("not an encoded maze")
+	// ErrUnsupportedVersion is returned when decoding data written by
+	// another version of the encoding
+	ErrUnsupportedVersion = errors.New("unsupported maze encoding version")
+	// ErrChecksum is returned when decoding data whose checksum does not
+	// match its contents
+	ErrChecksum = errors.New("maze checksum mismatch")
+	// ErrCorrupt is returned when decoding data whose checksum matches but
+	// whose contents do not describe a valid maze
+	ErrCorrupt = errors.New("corrupt maze encoding")
+)
+
+// MarshalBinary encodes the maze in a compact binary form, see
+// UnmarshalBinary. Only the built-in topologies can be encoded
+func (m *Maze) MarshalBinary() ([]byte, error) {
+	topology := m.Geometry().Name()
+	if _, ok := gridByName(topology); !ok {
+		return nil, fmt.Errorf("cannot encode maze: unknown topology %q", topology)
+	}
+
+	var flags byte
+	if m.Exit != nil {
+		flags |= binaryHasExit
+	}
+	if m.Goal != nil {
+		flags |= binaryHasGoal
+	}
+	if m.Mask != nil {
+		flags |= binaryHasMask
+	}
+	if m.crossings != nil {
+		flags |= binaryHasCrossings
+	}
+
+	data := append([]byte(binaryMagic), binaryVersion, flags)
+	data = binary.AppendUvarint(data, uint64(m.Width))
+	data = binary.AppendUvarint(data, uint64(m.Height))
+	data = binary.AppendVarint(data, m.Seed)
+	for _, name := range []string{m.Generator, topology} {
+		data = binary.AppendUvarint(data, uint64(len(name)))
+		data = append(data, name...)
+	}
+	data = appendPosition(data, m.Start)
+	if m.Exit != nil {
+		data = appendPosition(data, m.Exit.Position)
+		data = append(data, byte(m.Exit.Direction))
+	}
+	if m.Goal != nil {
+		data = appendPosition(data, *m.Goal)
+	}
+	if m.Mask != nil {
+		mask := newBitset(m.Width*m.Height, false)
+		for y := 0; y < m.Height; y++ {
+			for x := 0; x < m.Width; x++ {
+				mask.set(y*m.Width+x, m.Mask.Enabled(x, y))
+			}
+		}
+		data = appendBitset(data, mask, m.Width*m.Height)
+	}
+	indexes := m.wallIndexes()
+	walls := newBitset(len(indexes), false)
+	for i, index := range indexes {
+		walls.set(i, m.walls.get(index))
+	}
+	data = appendBitset(data, walls, len(indexes))
+	if m.crossings != nil {
+		data = appendBitset(data, m.crossings, m.Width*m.Height*2)
+	}
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data)), nil
+}
+
+// UnmarshalBinary decodes a maze encoded by MarshalBinary, replacing the
+// contents of m. It rejects data that is truncated, fails its checksum,
+// or describes an impossible maze
+func (m *Maze) UnmarshalBinary(data []byte) error {
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return ErrNotMaze
+	}
+	if version := data[len(binaryMagic)]; version != binaryVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+	if len(data) < len(binaryMagic)+2+crc32.Size {
+		return fmt.Errorf("%w: too short", ErrCorrupt)
+	}
+	body, sum := data[:len(data)-crc32.Size], data[len(data)-crc32.Size:]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(sum) {
+		return ErrChecksum
+	}
+
+	r := &binaryReader{data: body[len(binaryMagic)+1:]}
+	flags := r.byte("flags")
+	if flags&^binaryKnownFlags != 0 {
+		return fmt.Errorf("%w: unknown flags %#x", ErrCorrupt, flags)
+	}
+	width, height := r.int("width"), r.int("height")
+	seed := r.varint("seed")
+	generator, topologyName := r.string("generator name"), r.string("topology name")
+	if r.err != nil {
+		return r.err
+	}
+	if width <= 0 || height <= 0 || width > maxBinaryDimension || height > maxBinaryDimension {
+		return fmt.Errorf("%w: invalid dimensions %dx%d", ErrCorrupt, width, height)
+	}
+	grid, ok := gridByName(topologyName)
+	if !ok {
+		return fmt.Errorf("%w: unknown topology %q", ErrCorrupt, topologyName)
+	}
+	// Either the mask or the walls take at least a bit per cell, check they
+	// are there before allocating room for the maze
+	if (width*height+7)/8 > len(r.data) {
+		return fmt.Errorf("%w: truncated or invalid walls", ErrCorrupt)
+	}
+
+	maze := NewMaze(width, height)
+	if grid != GridSquare {
+		maze.Topology = grid.Topology(width, height)
+	}
+	maze.Seed = seed
+	maze.Generator = generator
+	maze.Start = r.position("start")
+	if flags&binaryHasExit != 0 {
+		exit := Exit{Position: r.position("exit")}
+		exit.Direction = MazeDirection(r.byte("exit direction"))
+		maze.Exit = &exit
+	}
+	if flags&binaryHasGoal != 0 {
+		goal := r.position("goal")
+		maze.Goal = &goal
+	}
+	if flags&binaryHasMask != 0 {
+		enabled := r.bitset("mask", width*height)
+		if r.err == nil {
+			maze.Mask = NewMask(width, height)
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					maze.Mask.Set(x, y, enabled.get(y*width+x))
+				}
+			}
+		}
+	}
+	indexes := maze.wallIndexes()
+	if walls := r.bitset("walls", len(indexes)); r.err == nil {
+		for i, index := range indexes {
+			maze.walls.set(index, walls.get(i))
+		}
+	}
+	if flags&binaryHasCrossings != 0 {
+		maze.crossings = r.bitset("crossings", width*height*2)
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) > 0 {
+		return fmt.Errorf("%w: %d unexpected bytes at the end", ErrCorrupt, len(r.data))
+	}
+
+	if !maze.IsValidPosition(maze.Start.X, maze.Start.Y) {
+		return fmt.Errorf("%w: start %v is outside the maze", ErrCorrupt, maze.Start)
+	}
+	if maze.Exit != nil {
+		if !maze.IsValidPosition(maze.Exit.Position.X, maze.Exit.Position.Y) {
+			return fmt.Errorf("%w: exit %v is outside the maze", ErrCorrupt, maze.Exit.Position)
+		}
+		if !maze.hasDirection(maze.Exit.Position.X, maze.Exit.Position.Y, maze.Exit.Direction) {
+			return fmt.Errorf("%w: invalid exit direction %d", ErrCorrupt, maze.Exit.Direction)
+		}
+		if !maze.isBoundaryWall(*maze.Exit) || maze.HasWall(maze.Exit.Position.X, maze.Exit.Position.Y, maze.Exit.Direction) {
+			return fmt.Errorf("%w: exit at %v does not lead %s out of the maze", ErrCorrupt, maze.Exit.Position, maze.Exit.Direction)
+		}
+	}
+	if maze.Goal != nil && !maze.IsValidPosition(maze.Goal.X, maze.Goal.Y) {
+		return fmt.Errorf("%w: goal %v is outside the maze", ErrCorrupt, *maze.Goal)
+	}
+	if err := maze.checkCrossings(); err != nil {
+		return fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+
+	*m = *maze
+	return nil
+}
+
+// maxBinaryDimension bounds the width and height UnmarshalBinary accepts
+const maxBinaryDimension = 1 << 16
+
+// wallIndexes returns the bits in m.walls of the walls of the cells of the
+// maze, each shared wall once, row by row and in the order of the
+// directions of each cell, followed by Up and Down. Mazes of the same size,
+// topology and mask have the same ones. Bits no cell can see, like those
+// of slots a topology does not use or walls between masked out cells, are
+// left out
+func (m *Maze) wallIndexes() []int {
+	seen := newBitset(len(m.walls)*64, false)
+	var indexes []int
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if !m.IsValidPosition(x, y) {
+				continue
+			}
+			for _, dirs := range [2][]MazeDirection{m.Directions(x, y), {Up, Down}} {
+				for _, dir := range dirs {
+					if i := m.wallIndex(x, y, dir); !seen.get(i) {
+						seen.set(i, true)
+						indexes = append(indexes, i)
+					}
+				}
+			}
+		}
+	}
+	return indexes
+}
+
+func appendPosition(data []byte, p Position) []byte {
+	data = binary.AppendUvarint(data, uint64(p.X))
+	return binary.AppendUvarint(data, uint64(p.Y))
+}
+
+// appendBitset appends the first n bits of the bitset, packed into bytes
+func appendBitset(data []byte, b bitset, n int) []byte {
+	for i := 0; i < (n+7)/8; i++ {
+		data = append(data, byte(b[i/8]>>(uint(i)%8*8)))
+	}
+	return data
+}
+
+// binaryReader reads the fields of an encoded maze one by one. After the
+// first error every read returns a zero value, and err says what went wrong
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+func (r *binaryReader) fail(field string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: truncated or invalid %s", ErrCorrupt, field)
+	}
+}
+
+func (r *binaryReader) byte(field string) byte {
+	if r.err != nil || len(r.data) == 0 {
+		r.fail(field)
+		return 0
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b
+}
+
+func (r *binaryReader) int(field string) int {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 || v > maxBinaryDimension*maxBinaryDimension {
+		r.fail(field)
+		return 0
+	}
+	r.data = r.data[n:]
+	return int(v)
+}
+
+func (r *binaryReader) varint(field string) int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.fail(field)
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *binaryReader) bytes(field string, n int) []byte {
+	if r.err != nil || n < 0 || n > len(r.data) {
+		r.fail(field)
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *binaryReader) string(field string) string {
+	return string(r.bytes(field, r.int(field)))
+}
+
+func (r *binaryReader) position(field string) Position {
+	return Position{X: r.int(field), Y: r.int(field)}
+}
+
+// bitset reads n bits packed into bytes by appendBitset
+func (r *binaryReader) bitset(field string, n int) bitset {
+	data := r.bytes(field, (n+7)/8)
+	if r.err != nil {
+		return nil
+	}
+	b := newBitset(n, false)
+	for i, v := range data {
+		b[i/8] |= uint64(v) << (uint(i) % 8 * 8)
+	}
+	return b
+}
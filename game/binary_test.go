@@ -0,0 +1,159 @@
+package game
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertSameMaze checks that two mazes have the same metadata and the same
+// walls everywhere
+func assertSameMaze(t *testing.T, want, got *Maze) {
+	t.Helper()
+	require.Equal(t, want.Width, got.Width)
+	require.Equal(t, want.Height, got.Height)
+	assert.Equal(t, want.Geometry().Name(), got.Geometry().Name())
+	assert.Equal(t, want.Start, got.Start)
+	assert.Equal(t, want.Exit, got.Exit)
+	assert.Equal(t, want.Goal, got.Goal)
+	assert.Equal(t, want.Seed, got.Seed)
+	assert.Equal(t, want.Generator, got.Generator)
+	if want.Mask == nil {
+		assert.Nil(t, got.Mask)
+	} else if assert.NotNil(t, got.Mask) {
+		assert.Equal(t, want.Mask.String(), got.Mask.String())
+	}
+	for y := 0; y < want.Height; y++ {
+		for x := 0; x < want.Width; x++ {
+			assert.Equal(t, want.Crossing(x, y), got.Crossing(x, y), "crossing at (%d, %d)", x, y)
+			for d := MazeDirection(0); int(d) < maxDirections; d++ {
+				assert.Equal(t, want.HasWall(x, y, d), got.HasWall(x, y, d), "wall at (%d, %d) %s", x, y, d)
+			}
+		}
+	}
+}
+
+func TestMazeBinaryRoundTrip(t *testing.T) {
+	mask, err := ParseMask(ringMask)
+	require.NoError(t, err)
+
+	configs := map[string]MazeConfig{
+		"square": {Width: 12, Height: 9, Seed: 3},
+		"weave":  {Width: 12, Height: 9, Seed: -4, Generator: WeaveGenerator{}},
+		"masked": {Mask: mask, Seed: 5, Generator: PrimGenerator{}},
+	}
+	for g := 1; g < gridCount; g++ {
+		configs[Grid(g).String()] = MazeConfig{Width: 8, Height: 6, Seed: int64(g), Grid: Grid(g)}
+	}
+
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			maze, _ := config.Generate()
+			data, err := maze.MarshalBinary()
+			require.NoError(t, err)
+
+			var decoded Maze
+			require.NoError(t, decoded.UnmarshalBinary(data))
+			assertSameMaze(t, maze, &decoded)
+
+			again, err := decoded.MarshalBinary()
+			require.NoError(t, err)
+			assert.Equal(t, data, again)
+		})
+	}
+}
+
+func TestMazeWallIndexes(t *testing.T) {
+	// Every cell has its north, east, up and down walls, and the cells on
+	// the south and west edges their walls on the outside too
+	assert.Len(t, NewMaze(12, 9).wallIndexes(), 12*9*4+12+9)
+
+	mask, err := ParseMask(ringMask)
+	require.NoError(t, err)
+	masked := NewMaskedMaze(mask)
+	assert.Less(t, len(masked.wallIndexes()), len(NewMaze(mask.Width, mask.Height).wallIndexes()), "masked out cells have no walls")
+}
+
+// withChecksum appends a valid checksum to the data
+func withChecksum(data []byte) []byte {
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+}
+
+func TestMazeBinaryErrors(t *testing.T) {
+	maze, _ := GenerateMaze(5, 4, 1)
+	data, err := maze.MarshalBinary()
+	require.NoError(t, err)
+	body := data[:len(data)-crc32.Size]
+
+	var decoded Maze
+	assert.ErrorIs(t, decoded.UnmarshalBinary(nil), ErrNotMaze)
+	assert.ErrorIs(t, decoded.UnmarshalBinary([]byte("+--+--+\n")), ErrNotMaze)
+
+	newer := append([]byte(nil), data...)
+	newer[len(binaryMagic)] = binaryVersion + 1
+	assert.ErrorIs(t, decoded.UnmarshalBinary(newer), ErrUnsupportedVersion)
+
+	// Flipping any bit after the version is caught
+	for i := len(binaryMagic) + 1; i < len(data); i++ {
+		corrupt := append([]byte(nil), data...)
+		corrupt[i] ^= 0x10
+		assert.ErrorIs(t, decoded.UnmarshalBinary(corrupt), ErrChecksum, "byte %d", i)
+	}
+	for n := len(binaryMagic) + 1; n < len(data); n++ {
+		assert.Error(t, decoded.UnmarshalBinary(data[:n]), "truncated to %d bytes", n)
+	}
+
+	// Data with a valid checksum must still make sense
+	for n := len(binaryMagic) + 1; n < len(body); n++ {
+		assert.ErrorIs(t, decoded.UnmarshalBinary(withChecksum(body[:n:n])), ErrCorrupt, "truncated to %d bytes", n)
+	}
+	assert.ErrorIs(t, decoded.UnmarshalBinary(withChecksum(append(body[:len(body):len(body)], 0))), ErrCorrupt)
+
+	header := append([]byte(binaryMagic), binaryVersion, 0)
+	huge := binary.AppendUvarint(append([]byte(nil), header...), 1<<15)
+	huge = binary.AppendUvarint(huge, 1<<15)
+	huge = append(huge, 0, 0, 6, 'S', 'q', 'u', 'a', 'r', 'e', 0, 0)
+	assert.ErrorIs(t, decoded.UnmarshalBinary(withChecksum(huge)), ErrCorrupt, "dimensions larger than the data")
+
+	outside := *maze
+	outside.Start = Position{9, 9}
+	encoded, err := outside.MarshalBinary()
+	require.NoError(t, err)
+	assert.ErrorIs(t, decoded.UnmarshalBinary(encoded), ErrCorrupt)
+
+	// The exit must be an opening in the outer wall
+	inside, _ := GenerateMaze(5, 4, 1)
+	inside.Exit = &Exit{Position: Position{1, 1}, Direction: East}
+	inside.RemoveWall(1, 1, East)
+	encoded, err = inside.MarshalBinary()
+	require.NoError(t, err)
+	assert.ErrorIs(t, decoded.UnmarshalBinary(encoded), ErrCorrupt, "exit into the maze")
+
+	walled := *maze
+	walled.Exit = &Exit{Position: Position{0, 0}, Direction: West}
+	encoded, err = walled.MarshalBinary()
+	require.NoError(t, err)
+	assert.ErrorIs(t, decoded.UnmarshalBinary(encoded), ErrCorrupt, "exit through a wall")
+
+	// Moves along the second row would wrap round it forever
+	torus := NewMazeWithTopology(3, 2, GridTorus.Topology(3, 2))
+	for x := 0; x < 3; x++ {
+		torus.RemoveWall(x, 1, East)
+		torus.SetCrossing(x, 1, CrossingEastWest)
+	}
+	encoded, err = torus.MarshalBinary()
+	require.NoError(t, err)
+	assert.ErrorIs(t, decoded.UnmarshalBinary(encoded), ErrCorrupt, "crossings that cannot be passed through")
+
+	assert.Equal(t, Maze{}, decoded, "failed decodes leave the maze alone")
+
+	_, err = NewMazeWithTopology(2, 2, customTopology{}).MarshalBinary()
+	assert.Error(t, err, "only the built-in topologies can be encoded")
+}
+
+type customTopology struct{ SquareTopology }
+
+func (customTopology) Name() string { return "Custom" }
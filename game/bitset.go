@@ -0,0 +1,29 @@
+package game
+
+// bitset is a fixed size set of bits packed into 64-bit words
+type bitset []uint64
+
+// newBitset returns a bitset of n bits, all set to the given value
+func newBitset(n int, value bool) bitset {
+	b := make(bitset, (n+63)/64)
+	if value {
+		for i := range b {
+			b[i] = ^uint64(0)
+		}
+	}
+	return b
+}
+
+// get reports whether bit i is set
+func (b bitset) get(i int) bool {
+	return b[uint(i)/64]&(1<<(uint(i)%64)) != 0
+}
+
+// set sets bit i to the given value
+func (b bitset) set(i int, value bool) {
+	if value {
+		b[uint(i)/64] |= 1 << (uint(i) % 64)
+	} else {
+		b[uint(i)/64] &^= 1 << (uint(i) % 64)
+	}
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+)
+
+// openSides returns how many of the cell's walls are missing
+func (m *Maze) openSides(x, y int) int {
+	open := 0
+	for _, dir := range m.Directions(x, y) {
+		if !m.HasWall(x, y, dir) {
+			open++
+		}
+	}
+	return open
+}
+
+// IsDeadEnd reports whether the cell at the given position has exactly one
+// way in or out
+func (m *Maze) IsDeadEnd(x, y int) bool {
+	return m.IsValidPosition(x, y) && m.openSides(x, y) == 1
+}
+
+// DeadEnds returns the positions of all dead ends, row by row
+func (m *Maze) DeadEnds() []Position {
+	var deadEnds []Position
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.IsDeadEnd(x, y) {
+				deadEnds = append(deadEnds, Position{X: x, Y: y})
+			}
+		}
+	}
+	return deadEnds
+}
+
+// Braid removes the given fraction, between 0 and 1, of the maze's dead ends
+// by knocking down one of their walls, which creates loops and therefore
+// alternative routes. Where possible it knocks through into another dead
+// end, so that one wall fixes two of them
+func (m *Maze) Braid(fraction float64, rng *rand.Rand) {
+	deadEnds := m.DeadEnds()
+	rng.Shuffle(len(deadEnds), func(i, j int) {
+		deadEnds[i], deadEnds[j] = deadEnds[j], deadEnds[i]
+	})
+
+	fraction = math.Max(0, math.Min(1, fraction))
+	remaining := len(deadEnds)
+	target := len(deadEnds) - int(math.Round(fraction*float64(len(deadEnds))))
+
+	for _, cell := range deadEnds {
+		if remaining <= target {
+			return
+		}
+		// An earlier wall may already have opened this one up
+		if !m.IsDeadEnd(cell.X, cell.Y) {
+			continue
+		}
+
+		var candidates, deadEndCandidates []MazeDirection
+		for _, dir := range m.Directions(cell.X, cell.Y) {
+			nx, ny := m.Neighbor(cell.X, cell.Y, dir)
+			if !m.IsValidPosition(nx, ny) || !m.HasWall(cell.X, cell.Y, dir) {
+				continue
+			}
+			candidates = append(candidates, dir)
+			if m.IsDeadEnd(nx, ny) {
+				deadEndCandidates = append(deadEndCandidates, dir)
+			}
+		}
+		if len(deadEndCandidates) > 0 {
+			candidates = deadEndCandidates
+			remaining--
+		}
+		if len(candidates) == 0 {
+			// Nowhere to go, e.g. the ends of a one cell wide maze
+			continue
+		}
+
+		m.RemoveWall(cell.X, cell.Y, candidates[rng.Intn(len(candidates))])
+		remaining--
+	}
+}
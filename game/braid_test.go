@@ -0,0 +1,57 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadEnds(t *testing.T) {
+	maze, err := ParseMaze(`+--+--+--+
+|        |
++--+  +--+
+|     |  |
++--+--+--+`)
+	assert.NoError(t, err)
+	assert.Equal(t, []Position{{0, 0}, {2, 0}, {0, 1}}, maze.DeadEnds())
+	assert.False(t, maze.IsDeadEnd(1, 0), "a junction is not a dead end")
+	assert.False(t, maze.IsDeadEnd(2, 1), "a closed cell is not a dead end")
+}
+
+func TestBraid(t *testing.T) {
+	generate := func(seed int64) *Maze {
+		maze := NewMaze(20, 20)
+		DFSGenerator{}.Generate(maze, rand.New(rand.NewSource(seed)))
+		return maze
+	}
+
+	t.Run("zero fraction leaves the maze alone", func(t *testing.T) {
+		maze := generate(1)
+		before := maze.String()
+		maze.Braid(0, rand.New(rand.NewSource(1)))
+		assert.Equal(t, before, maze.String())
+	})
+
+	t.Run("full fraction removes every dead end", func(t *testing.T) {
+		maze := generate(2)
+		assert.NotEmpty(t, maze.DeadEnds())
+		maze.Braid(1, rand.New(rand.NewSource(2)))
+		assert.Empty(t, maze.DeadEnds())
+	})
+
+	t.Run("half fraction removes about half the dead ends", func(t *testing.T) {
+		maze := generate(3)
+		before := len(maze.DeadEnds())
+		maze.Braid(0.5, rand.New(rand.NewSource(3)))
+		after := len(maze.DeadEnds())
+		assert.InDelta(t, before/2, after, 1)
+	})
+
+	t.Run("same seed braids the same way", func(t *testing.T) {
+		maze1, maze2 := generate(4), generate(4)
+		maze1.Braid(0.5, rand.New(rand.NewSource(4)))
+		maze2.Braid(0.5, rand.New(rand.NewSource(4)))
+		assert.Equal(t, maze1.String(), maze2.String())
+	})
+}
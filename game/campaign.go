@@ -0,0 +1,123 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// CampaignLevel is one step of a campaign. Its maze is generated from a
+// fixed seed, so every player gets the same one
+type CampaignLevel struct {
+	Name          string
+	Width, Height int
+	Speed         PlayerSpeed
+	Algorithm     MazeAlgorithm
+	Seed          int64
+}
+
+// MazeConfig returns the configuration of the level's maze
+func (l CampaignLevel) MazeConfig() MazeConfig {
+	return MazeConfig{
+		Width:     l.Width,
+		Height:    l.Height,
+		Seed:      l.Seed,
+		Generator: l.Algorithm.Generator(),
+		// Keep the start from landing right next to the exit
+		Placement:   PlacementMinDistance,
+		MinDistance: (l.Width + l.Height) / 2,
+	}
+}
+
+// Campaign is a sequence of levels, each unlocked by clearing the one
+// before it
+type Campaign struct {
+	// Name tells campaigns apart, and keys their saved progress
+	Name   string
+	Levels []CampaignLevel
+}
+
+// DefaultCampaign returns the campaign the game ships with. Its mazes get
+// bigger, the player turns faster, and the algorithms leave fewer obvious
+// routes as it goes
+func DefaultCampaign() Campaign {
+	return Campaign{
+		Name: "main",
+		Levels: []CampaignLevel{
+			{Name: "Warm Up", Width: 3, Height: 3, Speed: SpeedLow, Algorithm: AlgorithmBinaryTree, Seed: 304},
+			{Name: "Side Streets", Width: 4, Height: 4, Speed: SpeedLow, Algorithm: AlgorithmSidewinder, Seed: 1},
+			{Name: "First Turns", Width: 5, Height: 5, Speed: SpeedLow, Algorithm: AlgorithmBacktracker, Seed: 2},
+			{Name: "Branches", Width: 6, Height: 6, Speed: SpeedMedium, Algorithm: AlgorithmPrim, Seed: 3},
+			{Name: "Crossroads", Width: 8, Height: 8, Speed: SpeedMedium, Algorithm: AlgorithmKruskal, Seed: 4},
+			{Name: "Over and Under", Width: 10, Height: 10, Speed: SpeedMedium, Algorithm: AlgorithmWeave, Seed: 5},
+			{Name: "Random Walk", Width: 12, Height: 12, Speed: SpeedMedium, Algorithm: AlgorithmWilson, Seed: 6},
+			{Name: "Long Way Round", Width: 14, Height: 14, Speed: SpeedHigh, Algorithm: AlgorithmBacktracker, Seed: 7},
+			{Name: "Hunting Grounds", Width: 16, Height: 16, Speed: SpeedHigh, Algorithm: AlgorithmHuntAndKill, Seed: 8},
+			{Name: "The Labyrinth", Width: 20, Height: 20, Speed: SpeedHigh, Algorithm: AlgorithmBacktracker, Seed: 9},
+		},
+	}
+}
+
+// CampaignProgress records how far a player got through a campaign
+type CampaignProgress struct {
+	// Cleared is how many levels have been cleared, in order. The level
+	// after them is the furthest one unlocked
+	Cleared int `json:"cleared"`
+}
+
+// progressKey is the key the progress through the campaign is saved under
+func (c Campaign) progressKey() string {
+	return "campaign-" + c.Name
+}
+
+// LoadProgress returns the progress through the campaign saved in the
+// store. A player with nothing saved starts from the beginning, and so
+// does one whose progress cannot be read, along with the error
+func (c Campaign) LoadProgress(store Store) (CampaignProgress, error) {
+	var p CampaignProgress
+	data, err := store.Load(c.progressKey())
+	if errors.Is(err, ErrNotStored) {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return CampaignProgress{}, fmt.Errorf("invalid progress for campaign %q: %w", c.Name, err)
+	}
+	p.Cleared = max(0, min(p.Cleared, len(c.Levels)))
+	return p, nil
+}
+
+// SaveProgress saves the progress through the campaign in the store
+func (c Campaign) SaveProgress(store Store, p CampaignProgress) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return store.Save(c.progressKey(), data)
+}
+
+// IsUnlocked reports whether the level, counting from 0, can be played
+func (c Campaign) IsUnlocked(p CampaignProgress, level int) bool {
+	return level >= 0 && level < len(c.Levels) && level <= p.Cleared
+}
+
+// IsFinished reports whether every level of the campaign has been cleared
+func (c Campaign) IsFinished(p CampaignProgress) bool {
+	return p.Cleared >= len(c.Levels)
+}
+
+// Complete records clearing the level, counting from 0, and reports
+// whether that unlocked a new one. Replaying a level cleared before
+// changes nothing
+func (c Campaign) Complete(p *CampaignProgress, level int) (bool, error) {
+	if !c.IsUnlocked(*p, level) {
+		return false, fmt.Errorf("level %d of campaign %q is locked", level+1, c.Name)
+	}
+	if level < p.Cleared {
+		return false, nil
+	}
+	p.Cleared++
+	return !c.IsFinished(*p), nil
+}
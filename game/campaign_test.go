@@ -0,0 +1,80 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultCampaign(t *testing.T) {
+	campaign := DefaultCampaign()
+	require.NotEmpty(t, campaign.Levels)
+
+	previous := 0
+	for _, level := range campaign.Levels {
+		maze, _ := level.MazeConfig().Generate()
+		assert.NoError(t, maze.Validate(maze.Start).Err(), level.Name)
+		assert.GreaterOrEqual(t, level.Width*level.Height, previous, "%s should be no smaller than the level before", level.Name)
+		previous = level.Width * level.Height
+
+		again, _ := level.MazeConfig().Generate()
+		assert.Equal(t, maze.MarkedString(), again.MarkedString(), "%s should be the same for every player", level.Name)
+	}
+}
+
+func TestCampaignProgress(t *testing.T) {
+	campaign := Campaign{Name: "test", Levels: make([]CampaignLevel, 3)}
+	var p CampaignProgress
+	assert.True(t, campaign.IsUnlocked(p, 0))
+	assert.False(t, campaign.IsUnlocked(p, 1))
+
+	_, err := campaign.Complete(&p, 1)
+	assert.Error(t, err, "locked levels cannot be cleared")
+
+	unlocked, err := campaign.Complete(&p, 0)
+	require.NoError(t, err)
+	assert.True(t, unlocked)
+	assert.True(t, campaign.IsUnlocked(p, 1))
+
+	unlocked, err = campaign.Complete(&p, 0)
+	require.NoError(t, err)
+	assert.False(t, unlocked, "replaying a cleared level unlocks nothing")
+	assert.Equal(t, 1, p.Cleared)
+
+	_, _ = campaign.Complete(&p, 1)
+	unlocked, err = campaign.Complete(&p, 2)
+	require.NoError(t, err)
+	assert.False(t, unlocked, "there is nothing after the last level")
+	assert.True(t, campaign.IsFinished(p))
+	assert.False(t, campaign.IsUnlocked(p, 3))
+}
+
+func TestCampaignSavedProgress(t *testing.T) {
+	campaign := Campaign{Name: "test", Levels: make([]CampaignLevel, 3)}
+	store := MemoryStore{}
+
+	p, err := campaign.LoadProgress(store)
+	require.NoError(t, err)
+	assert.Zero(t, p.Cleared, "nothing saved starts from the beginning")
+
+	require.NoError(t, campaign.SaveProgress(store, CampaignProgress{Cleared: 2}))
+	p, err = campaign.LoadProgress(store)
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.Cleared)
+
+	other := Campaign{Name: "other", Levels: make([]CampaignLevel, 3)}
+	p, err = other.LoadProgress(store)
+	require.NoError(t, err)
+	assert.Zero(t, p.Cleared, "campaigns keep their own progress")
+
+	store["campaign-test"] = []byte(`{"cleared": 99}`)
+	p, err = campaign.LoadProgress(store)
+	require.NoError(t, err)
+	assert.Equal(t, 3, p.Cleared, "progress is kept within the campaign")
+
+	store["campaign-test"] = []byte(`garbage`)
+	p, err = campaign.LoadProgress(store)
+	assert.Error(t, err)
+	assert.Zero(t, p.Cleared)
+}
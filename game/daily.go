@@ -0,0 +1,150 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"time"
+)
+
+// dailyDateLayout is how the dates of daily challenges are written
+const dailyDateLayout = "2006-01-02"
+
+// dailyRecordsKey is the key the results of daily challenges are saved under
+const dailyRecordsKey = "daily"
+
+// dailyRules are the choices the challenge of each day is picked from. They
+// are part of the challenge: adding an algorithm to a list would change
+// the maze of every day, past ones included, and leave recorded results
+// pointing at another maze. New choices need new rules with a new version
+type dailyRules struct {
+	version    int
+	algorithms []MazeAlgorithm
+	speeds     []PlayerSpeed
+	// Mazes are between minSize and maxSize cells wide and high
+	minSize, maxSize int
+}
+
+// dailyRulesV1 are the rules challenges are currently made with
+var dailyRulesV1 = dailyRules{
+	version: 1,
+	algorithms: []MazeAlgorithm{
+		AlgorithmBacktracker,
+		AlgorithmPrim,
+		AlgorithmKruskal,
+		AlgorithmWilson,
+		AlgorithmAldousBroder,
+		AlgorithmEller,
+		AlgorithmHuntAndKill,
+		AlgorithmSidewinder,
+		AlgorithmBinaryTree,
+		AlgorithmRecursiveDivision,
+		AlgorithmWeave,
+	},
+	speeds:  []PlayerSpeed{SpeedLow, SpeedMedium, SpeedHigh},
+	minSize: 6,
+	maxSize: 15,
+}
+
+// Daily is the challenge of one day. Everything about its maze is derived
+// from the date, so everyone playing on the same day gets the same one
+type Daily struct {
+	Date string // In UTC, as YYYY-MM-DD
+	// Version is the version of the rules the challenge was made with
+	Version       int
+	Width, Height int
+	Speed         PlayerSpeed
+	Algorithm     MazeAlgorithm
+	Seed          int64
+}
+
+// DailyFor returns the challenge of the day the time falls on in UTC
+func DailyFor(t time.Time) Daily {
+	return dailyRulesV1.challenge(t)
+}
+
+func (r dailyRules) challenge(t time.Time) Daily {
+	date := t.UTC().Format(dailyDateLayout)
+	h := fnv.New64a()
+	h.Write([]byte(date))
+	seed := int64(h.Sum64() &^ (1 << 63)) // Seeds are non-negative, like those of NewSeed
+
+	// math/rand sources produce the same values for the same seed on every
+	// platform and Go version, which keeps the challenge shared
+	rng := rand.New(rand.NewSource(seed))
+	return Daily{
+		Date:      date,
+		Version:   r.version,
+		Width:     r.minSize + rng.Intn(r.maxSize-r.minSize+1),
+		Height:    r.minSize + rng.Intn(r.maxSize-r.minSize+1),
+		Speed:     r.speeds[rng.Intn(len(r.speeds))],
+		Algorithm: r.algorithms[rng.Intn(len(r.algorithms))],
+		Seed:      seed,
+	}
+}
+
+// MazeConfig returns the configuration of the challenge's maze
+func (d Daily) MazeConfig() MazeConfig {
+	return MazeConfig{
+		Width:     d.Width,
+		Height:    d.Height,
+		Seed:      d.Seed,
+		Generator: d.Algorithm.Generator(),
+		// Keep the start from landing right next to the exit
+		Placement:   PlacementMinDistance,
+		MinDistance: (d.Width + d.Height) / 2,
+	}
+}
+
+// DailyResult is how a daily challenge went
+type DailyResult struct {
+	Time    time.Duration `json:"time"`
+	Presses int           `json:"presses"`
+	// Version is the version of the rules the challenge was made with, to
+	// tell which maze the result was for
+	Version int `json:"version"`
+}
+
+// DailyRecords holds the results of daily challenges by date. Only the
+// first completion of each day counts, later ones are practice
+type DailyRecords map[string]DailyResult
+
+// LoadDailyRecords returns the results of daily challenges saved in the
+// store. A player with nothing saved has no results, and so does one whose
+// results cannot be read, along with the error
+func LoadDailyRecords(store Store) (DailyRecords, error) {
+	records := DailyRecords{}
+	data, err := store.Load(dailyRecordsKey)
+	if errors.Is(err, ErrNotStored) {
+		return records, nil
+	}
+	if err != nil {
+		return records, err
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return DailyRecords{}, fmt.Errorf("invalid daily challenge results: %w", err)
+	}
+	return records, nil
+}
+
+// SaveDailyRecords saves the results of daily challenges in the store
+func SaveDailyRecords(store Store, records DailyRecords) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	return store.Save(dailyRecordsKey, data)
+}
+
+// Record records completing the challenge of the date, and reports whether
+// it was practice: a replay of a challenge already completed, whose result
+// is kept instead
+func (r DailyRecords) Record(date string, result DailyResult) (practice bool) {
+	if _, ok := r[date]; ok {
+		return true
+	}
+	r[date] = result
+	return false
+}
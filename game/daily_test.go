@@ -0,0 +1,62 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyFor(t *testing.T) {
+	morning := time.Date(2024, 3, 9, 0, 30, 0, 0, time.UTC)
+	evening := time.Date(2024, 3, 9, 23, 30, 0, 0, time.UTC)
+	daily := DailyFor(morning)
+	assert.Equal(t, Daily{
+		Date:      "2024-03-09",
+		Version:   1,
+		Width:     14,
+		Height:    11,
+		Speed:     SpeedMedium,
+		Algorithm: AlgorithmRecursiveDivision,
+		Seed:      6309962583554220131,
+	}, daily, "past challenges must not change")
+	assert.Equal(t, daily, DailyFor(evening), "the whole day shares a challenge")
+
+	// Dates are taken in UTC, wherever the player is
+	tokyo := time.FixedZone("JST", 9*60*60)
+	assert.Equal(t, daily, DailyFor(time.Date(2024, 3, 10, 8, 0, 0, 0, tokyo)))
+
+	next := DailyFor(morning.AddDate(0, 0, 1))
+	assert.Equal(t, "2024-03-10", next.Date)
+	assert.NotEqual(t, daily.Seed, next.Seed)
+
+	for day := 0; day < 30; day++ {
+		d := DailyFor(morning.AddDate(0, 0, day))
+		assert.GreaterOrEqual(t, d.Seed, int64(0))
+		maze, _ := d.MazeConfig().Generate()
+		assert.NoError(t, maze.Validate(maze.Start).Err(), d.Date)
+	}
+}
+
+func TestDailyRecords(t *testing.T) {
+	store := MemoryStore{}
+	records, err := LoadDailyRecords(store)
+	require.NoError(t, err)
+	assert.Empty(t, records, "nothing saved has no results")
+
+	first := DailyResult{Time: 42 * time.Second, Presses: 30}
+	assert.False(t, records.Record("2024-03-09", first))
+	assert.True(t, records.Record("2024-03-09", DailyResult{Time: 10 * time.Second, Presses: 8}), "replays are practice")
+	assert.Equal(t, first, records["2024-03-09"], "practice does not replace the result")
+	assert.False(t, records.Record("2024-03-10", first))
+
+	require.NoError(t, SaveDailyRecords(store, records))
+	loaded, err := LoadDailyRecords(store)
+	require.NoError(t, err)
+	assert.Equal(t, records, loaded)
+
+	require.NoError(t, store.Save(dailyRecordsKey, []byte("{")))
+	_, err = LoadDailyRecords(store)
+	assert.Error(t, err)
+}
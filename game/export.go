@@ -0,0 +1,293 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+)
+
+// ExportOptions configures how WriteSVG, WritePNG and Image draw a maze.
+// The zero value draws black walls on white with no overlays
+type ExportOptions struct {
+	// CellSize is the size of a cell unit in pixels; zero means 20
+	CellSize float64
+	// WallThickness is the width of the walls in pixels; zero means 2
+	WallThickness float64
+	// Margin is the space around the maze in pixels; zero means half a cell
+	Margin float64
+	// Background, WallColor, StartColor, ExitColor and PathColor are the
+	// colours of each part of the drawing; nil means the default
+	Background color.Color
+	WallColor  color.Color
+	StartColor color.Color
+	ExitColor  color.Color
+	PathColor  color.Color
+	// ShowStart marks the start with a dot, ShowExit marks the way out
+	// with an arrow, or the goal with a dot, and ShowSolution draws the
+	// shortest path from the start to the exit
+	ShowStart    bool
+	ShowExit     bool
+	ShowSolution bool
+}
+
+var (
+	defaultExportBackground = color.RGBA{255, 255, 255, 255}
+	defaultExportWallColor  = color.RGBA{0, 0, 0, 255}
+	defaultExportStartColor = color.RGBA{255, 200, 0, 255}
+	defaultExportExitColor  = color.RGBA{0, 180, 0, 255}
+	defaultExportPathColor  = color.RGBA{80, 140, 255, 255}
+)
+
+// withDefaults returns the options with every unset field filled in
+func (o ExportOptions) withDefaults() ExportOptions {
+	if o.CellSize <= 0 {
+		o.CellSize = 20
+	}
+	if o.WallThickness <= 0 {
+		o.WallThickness = 2
+	}
+	if o.Margin <= 0 {
+		o.Margin = o.CellSize / 2
+	}
+	for _, c := range []struct {
+		color    *color.Color
+		fallback color.Color
+	}{
+		{&o.Background, defaultExportBackground},
+		{&o.WallColor, defaultExportWallColor},
+		{&o.StartColor, defaultExportStartColor},
+		{&o.ExitColor, defaultExportExitColor},
+		{&o.PathColor, defaultExportPathColor},
+	} {
+		if *c.color == nil {
+			*c.color = c.fallback
+		}
+	}
+	return o
+}
+
+// drawing is a maze laid out in pixels, ready to be written in any format
+type drawing struct {
+	width, height int
+	background    color.Color
+	shapes        []shape
+}
+
+// shape is a line through the given points, or a filled circle round the
+// only point when radius is set
+type shape struct {
+	points []Point
+	width  float64
+	radius float64
+	color  color.Color
+}
+
+// layout lays out the maze and the overlays the options ask for
+func (m *Maze) layout(o ExportOptions) drawing {
+	o = o.withDefaults()
+	topology := m.Geometry()
+	unitsX, unitsY := topology.Size(m.Width, m.Height)
+	d := drawing{
+		width:      int(math.Ceil(unitsX*o.CellSize + 2*o.Margin)),
+		height:     int(math.Ceil(unitsY*o.CellSize + 2*o.Margin)),
+		background: o.Background,
+	}
+	toPixels := func(points []Point) []Point {
+		pixels := make([]Point, len(points))
+		for i, p := range points {
+			pixels[i] = Point{X: o.Margin + p.X*o.CellSize, Y: o.Margin + p.Y*o.CellSize}
+		}
+		return pixels
+	}
+	line := func(points []Point, width float64, c color.Color) {
+		d.shapes = append(d.shapes, shape{points: toPixels(points), width: width, color: c})
+	}
+	dot := func(p Point, c color.Color) {
+		d.shapes = append(d.shapes, shape{points: toPixels([]Point{p}), radius: o.CellSize / 4, color: c})
+	}
+
+	if o.ShowSolution {
+		if path, ok := m.ShortestPath(m.Start); ok && path.Len() > 0 {
+			var points []Point
+			for _, p := range path.Positions {
+				points = append(points, topology.Center(p.X, p.Y))
+			}
+			last := path.Positions[len(path.Positions)-1]
+			points = append(points, topology.Center(m.step(last.X, last.Y, path.Directions[len(path.Directions)-1])))
+			line(points, o.CellSize/4, o.PathColor)
+		}
+	}
+
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if !m.IsValidPosition(x, y) {
+				continue
+			}
+			if crossing := m.Crossing(x, y); crossing != NoCrossing {
+				for _, l := range CrossingLines(topology, x, y, crossing) {
+					line(l, o.WallThickness, o.WallColor)
+				}
+				continue
+			}
+			for _, dir := range m.Directions(x, y) {
+				if !m.HasWall(x, y, dir) {
+					continue
+				}
+				// Shared walls are drawn once, from the first of the two
+				// cells, unless the maze wraps round and they show twice
+				nx, ny := m.Neighbor(x, y, dir)
+				if m.IsValidPosition(nx, ny) && ny*m.Width+nx < y*m.Width+x && !IsWrapped(topology, x, y, dir) {
+					continue
+				}
+				line(topology.Wall(x, y, dir), o.WallThickness, o.WallColor)
+			}
+		}
+	}
+
+	if o.ShowExit {
+		if m.Exit != nil {
+			// An arrow from the exit cell out through the opening, ending
+			// inside the margin
+			from := topology.Center(m.Exit.Position.X, m.Exit.Position.Y)
+			outside := topology.Center(m.Neighbor(m.Exit.Position.X, m.Exit.Position.Y, m.Exit.Direction))
+			dx, dy := (outside.X-from.X)*0.75, (outside.Y-from.Y)*0.75
+			to := Point{X: from.X + dx, Y: from.Y + dy}
+			head := func(angle float64) Point {
+				sin, cos := math.Sincos(angle)
+				return Point{X: to.X - 0.4*(dx*cos-dy*sin), Y: to.Y - 0.4*(dx*sin+dy*cos)}
+			}
+			line([]Point{from, to}, o.CellSize/6, o.ExitColor)
+			line([]Point{head(math.Pi / 6), to, head(-math.Pi / 6)}, o.CellSize/6, o.ExitColor)
+		}
+		if m.Goal != nil {
+			dot(topology.Center(m.Goal.X, m.Goal.Y), o.ExitColor)
+		}
+	}
+	if o.ShowStart {
+		dot(topology.Center(m.Start.X, m.Start.Y), o.StartColor)
+	}
+	return d
+}
+
+// WriteSVG writes the maze as an SVG image
+func (m *Maze) WriteSVG(w io.Writer, o ExportOptions) error {
+	d := m.layout(o)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		d.width, d.height, d.width, d.height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" %s/>`+"\n", svgPaint("fill", d.background))
+	for _, s := range d.shapes {
+		if s.radius > 0 {
+			fmt.Fprintf(bw, `<circle cx="%s" cy="%s" r="%s" %s/>`+"\n",
+				svgNumber(s.points[0].X), svgNumber(s.points[0].Y), svgNumber(s.radius), svgPaint("fill", s.color))
+			continue
+		}
+		var points strings.Builder
+		for i, p := range s.points {
+			if i > 0 {
+				points.WriteByte(' ')
+			}
+			points.WriteString(svgNumber(p.X) + "," + svgNumber(p.Y))
+		}
+		fmt.Fprintf(bw, `<polyline points="%s" fill="none" stroke-width="%s" stroke-linecap="round" stroke-linejoin="round" %s/>`+"\n",
+			points.String(), svgNumber(s.width), svgPaint("stroke", s.color))
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// svgNumber formats a coordinate with at most two decimals
+func svgNumber(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// svgPaint returns the attributes painting a fill or stroke in the colour
+func svgPaint(attribute string, c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	paint := fmt.Sprintf(`%s="#%02x%02x%02x"`, attribute, n.R, n.G, n.B)
+	if n.A < 255 {
+		paint += fmt.Sprintf(` %s-opacity="%s"`, attribute, svgNumber(float64(n.A)/255))
+	}
+	return paint
+}
+
+// Image draws the maze into a new image, with anti-aliased edges
+func (m *Maze) Image(o ExportOptions) *image.RGBA {
+	d := m.layout(o)
+	img := image.NewRGBA(image.Rect(0, 0, d.width, d.height))
+	bg := color.RGBAModel.Convert(d.background).(color.RGBA)
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = bg.R, bg.G, bg.B, bg.A
+	}
+	for _, s := range d.shapes {
+		if s.radius > 0 {
+			fillDisc(img, s.points[0], s.radius, s.color)
+			continue
+		}
+		for i := 1; i < len(s.points); i++ {
+			strokeSegment(img, s.points[i-1], s.points[i], s.width/2, s.color)
+		}
+	}
+	return img
+}
+
+// WritePNG writes the maze as a PNG image
+func (m *Maze) WritePNG(w io.Writer, o ExportOptions) error {
+	return png.Encode(w, m.Image(o))
+}
+
+// strokeSegment paints every pixel within the given distance of the segment
+// from a to b, with round ends
+func strokeSegment(img *image.RGBA, a, b Point, halfWidth float64, c color.Color) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length2 := dx*dx + dy*dy
+	paintNear(img, math.Min(a.X, b.X)-halfWidth, math.Min(a.Y, b.Y)-halfWidth,
+		math.Max(a.X, b.X)+halfWidth, math.Max(a.Y, b.Y)+halfWidth, c,
+		func(p Point) float64 {
+			t := 0.0
+			if length2 > 0 {
+				t = math.Max(0, math.Min(1, ((p.X-a.X)*dx+(p.Y-a.Y)*dy)/length2))
+			}
+			return halfWidth - math.Hypot(p.X-a.X-t*dx, p.Y-a.Y-t*dy)
+		})
+}
+
+// fillDisc paints every pixel within radius of the center
+func fillDisc(img *image.RGBA, center Point, radius float64, c color.Color) {
+	paintNear(img, center.X-radius, center.Y-radius, center.X+radius, center.Y+radius, c,
+		func(p Point) float64 {
+			return radius - math.Hypot(p.X-center.X, p.Y-center.Y)
+		})
+}
+
+// paintNear blends the colour into the pixels of the bounding box whose
+// centers are inside a shape, given how far inside each point is. Pixels
+// within half a pixel of the edge are partly covered
+func paintNear(img *image.RGBA, minX, minY, maxX, maxY float64, c color.Color, inside func(Point) float64) {
+	r, g, b, a := c.RGBA()
+	bounds := img.Bounds()
+	x0, y0 := max(bounds.Min.X, int(math.Floor(minX-1))), max(bounds.Min.Y, int(math.Floor(minY-1)))
+	x1, y1 := min(bounds.Max.X, int(math.Ceil(maxX+1))), min(bounds.Max.Y, int(math.Ceil(maxY+1)))
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			coverage := math.Min(1, inside(Point{X: float64(x) + 0.5, Y: float64(y) + 0.5})+0.5)
+			if coverage <= 0 {
+				continue
+			}
+			// Source over destination, with premultiplied alpha
+			alpha := coverage * float64(a) / 0xffff
+			i := img.PixOffset(x, y)
+			pix := img.Pix[i : i+4 : i+4]
+			for j, v := range [4]uint32{r, g, b, a} {
+				pix[j] = uint8(float64(v>>8)*coverage + float64(pix[j])*(1-alpha) + 0.5)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package game
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSVG(t *testing.T) {
+	maze, err := ParseMaze(solverMaze)
+	require.NoError(t, err)
+	maze.Start = Position{0, 0}
+
+	var plain bytes.Buffer
+	require.NoError(t, maze.WriteSVG(&plain, ExportOptions{}))
+	elements := svgElements(t, plain.String())
+	assert.Equal(t, 1, elements["svg"])
+	assert.Equal(t, 1, elements["rect"])
+	assert.Zero(t, elements["circle"], "no overlays unless asked for")
+	assert.Contains(t, plain.String(), `width="80" height="60"`, "3x2 cells of 20 pixels and a margin of 10")
+
+	var overlays bytes.Buffer
+	require.NoError(t, maze.WriteSVG(&overlays, ExportOptions{
+		CellSize:     10,
+		WallColor:    color.RGBA{255, 0, 0, 255},
+		StartColor:   color.NRGBA{0, 0, 255, 128},
+		ShowStart:    true,
+		ShowExit:     true,
+		ShowSolution: true,
+	}))
+	withOverlays := svgElements(t, overlays.String())
+	assert.Equal(t, 1, withOverlays["circle"])
+	assert.Equal(t, elements["polyline"]+3, withOverlays["polyline"], "a solution line and an arrow of two lines")
+	assert.Contains(t, overlays.String(), `stroke="#ff0000"`)
+	assert.Contains(t, overlays.String(), `fill="#0000ff" fill-opacity="0.5"`)
+	assert.Contains(t, overlays.String(), `width="40" height="30"`)
+}
+
+// svgElements parses the SVG and counts its elements by name
+func svgElements(t *testing.T, svg string) map[string]int {
+	t.Helper()
+	counts := map[string]int{}
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return counts
+		}
+		require.NoError(t, err)
+		if start, ok := token.(xml.StartElement); ok {
+			counts[start.Name.Local]++
+		}
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	maze, err := ParseMaze(solverMaze)
+	require.NoError(t, err)
+	maze.Start = Position{0, 0}
+
+	var buf bytes.Buffer
+	options := ExportOptions{CellSize: 10, Margin: 5, ShowStart: true, ShowSolution: true}
+	require.NoError(t, maze.WritePNG(&buf, options))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, 40, img.Bounds().Dx())
+	require.Equal(t, 30, img.Bounds().Dy())
+
+	rgba := func(x, y int) color.RGBA {
+		return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+	}
+	assert.Equal(t, defaultExportBackground, rgba(1, 1), "margin")
+	assert.Equal(t, defaultExportWallColor, rgba(5, 20), "outer wall")
+	assert.Equal(t, defaultExportStartColor, rgba(10, 10), "start")
+
+	// The solution runs through the middle of the cells along the way
+	path, ok := maze.ShortestPath(maze.Start)
+	require.True(t, ok)
+	for _, p := range path.Positions[1:] {
+		assert.Equal(t, defaultExportPathColor, rgba(5+p.X*10+5, 5+p.Y*10+5), "path at %v", p)
+	}
+
+	// Every grid draws without trouble
+	for g := 0; g < gridCount; g++ {
+		maze, _ := MazeConfig{Width: 6, Height: 5, Grid: Grid(g), Generator: WeaveGenerator{}}.Generate()
+		img := maze.Image(ExportOptions{ShowStart: true, ShowExit: true, ShowSolution: true})
+		assert.Positive(t, img.Bounds().Dx(), Grid(g).String())
+	}
+}
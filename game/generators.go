@@ -0,0 +1,696 @@
+package game
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// Generator carves passages into a maze whose cells start with walls on all
+// sides, leaving a perfect maze: every cell is reachable from every other
+// cell by exactly one path. Generators never carve into masked out cells,
+// but the ones that work row by row may leave a masked maze disconnected,
+// which MazeConfig.Generate repairs. The same goes for topologies other
+// than square, where those generators only use the north, east and south
+// walls of the cells that have them
+type Generator interface {
+	// Name returns a short human readable name for the algorithm
+	Name() string
+	// Generate carves the passages, drawing all randomness from rng
+	Generate(maze *Maze, rng *rand.Rand)
+}
+
+// rootedGenerator is a Generator that grows the maze out of a single cell,
+// and can be told which one. MazeConfig grows it out of the player's start,
+// so that seeds give the same mazes they did before generators could be
+// chosen
+type rootedGenerator interface {
+	Generator
+	generateFrom(maze *Maze, root Position, rng *rand.Rand)
+}
+
+// MazeAlgorithm selects one of the built-in generators
+type MazeAlgorithm int
+
+const (
+	AlgorithmBacktracker MazeAlgorithm = iota
+	AlgorithmPrim
+	AlgorithmKruskal
+	AlgorithmWilson
+	AlgorithmAldousBroder
+	AlgorithmEller
+	AlgorithmHuntAndKill
+	AlgorithmSidewinder
+	AlgorithmBinaryTree
+	AlgorithmRecursiveDivision
+	AlgorithmWeave
+
+	mazeAlgorithmCount = int(AlgorithmWeave) + 1
+)
+
+// Next returns the algorithm after this one, wrapping round to the first
+func (a MazeAlgorithm) Next() MazeAlgorithm {
+	return MazeAlgorithm((int(a) + 1) % mazeAlgorithmCount)
+}
+
+func (a MazeAlgorithm) String() string {
+	return a.Generator().Name()
+}
+
+// Generator returns the generator implementing the algorithm
+func (a MazeAlgorithm) Generator() Generator {
+	switch a {
+	case AlgorithmBacktracker:
+		return DFSGenerator{}
+	case AlgorithmPrim:
+		return PrimGenerator{}
+	case AlgorithmKruskal:
+		return KruskalGenerator{}
+	case AlgorithmWilson:
+		return WilsonGenerator{}
+	case AlgorithmAldousBroder:
+		return AldousBroderGenerator{}
+	case AlgorithmEller:
+		return EllerGenerator{}
+	case AlgorithmHuntAndKill:
+		return HuntAndKillGenerator{}
+	case AlgorithmSidewinder:
+		return SidewinderGenerator{}
+	case AlgorithmBinaryTree:
+		return BinaryTreeGenerator{}
+	case AlgorithmRecursiveDivision:
+		return RecursiveDivisionGenerator{}
+	case AlgorithmWeave:
+		return WeaveGenerator{}
+	default:
+		return DFSGenerator{}
+	}
+}
+
+// MazeAlgorithmByName returns the algorithm whose generator has the given
+// name. Case, spaces and hyphens are ignored, so that "binarytree" and
+// "hunt-and-kill" can be typed on a command line
+func MazeAlgorithmByName(name string) (MazeAlgorithm, bool) {
+	normalize := strings.NewReplacer(" ", "", "-", "")
+	for a := 0; a < mazeAlgorithmCount; a++ {
+		if strings.EqualFold(normalize.Replace(MazeAlgorithm(a).String()), normalize.Replace(name)) {
+			return MazeAlgorithm(a), true
+		}
+	}
+	return AlgorithmBacktracker, false
+}
+
+// DFSGenerator implements the recursive backtracker: a depth-first search
+// that produces long, winding corridors with few branches
+type DFSGenerator struct{}
+
+func (DFSGenerator) Name() string { return "Backtracker" }
+
+func (g DFSGenerator) Generate(maze *Maze, rng *rand.Rand) {
+	g.generateFrom(maze, maze.randomCell(rng), rng)
+}
+
+func (DFSGenerator) generateFrom(maze *Maze, root Position, rng *rand.Rand) {
+	generateMazeDFS(maze, root.X, root.Y, rng)
+}
+
+// dfsFrame is a cell on the backtracker's stack, with the order in which
+// its neighbors are tried and how many of them have been tried so far
+type dfsFrame struct {
+	x, y       int32
+	next       uint8
+	count      uint8
+	directions [maxDirections]uint8
+}
+
+// generateMazeDFS implements the depth-first search algorithm starting at
+// (x, y). It keeps its own stack instead of recursing, so it runs in linear
+// time and constant call depth however large the maze is, and it consumes
+// randomness in the same order as the recursive formulation
+func generateMazeDFS(maze *Maze, x, y int, rng *rand.Rand) {
+	width := maze.Width
+	visited := make([]bool, width*maze.Height)
+	stack := make([]dfsFrame, 0, 64)
+
+	push := func(x, y int) {
+		visited[y*width+x] = true
+
+		// Define possible directions in a random order
+		frame := dfsFrame{x: int32(x), y: int32(y)}
+		for _, dir := range maze.Directions(x, y) {
+			frame.directions[frame.count] = uint8(dir)
+			frame.count++
+		}
+		for i := int(frame.count) - 1; i > 0; i-- {
+			j := rng.Intn(i + 1)
+			frame.directions[i], frame.directions[j] = frame.directions[j], frame.directions[i]
+		}
+		stack = append(stack, frame)
+	}
+
+	push(x, y)
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next == top.count {
+			// Every direction has been tried, backtrack
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		dir := MazeDirection(top.directions[top.next])
+		top.next++
+
+		x, y := int(top.x), int(top.y)
+		newX, newY := maze.Neighbor(x, y, dir)
+
+		// Check if the new position is valid and unvisited
+		if maze.IsValidPosition(newX, newY) && !visited[newY*width+newX] {
+			// Remove walls between current and new position
+			maze.RemoveWall(x, y, dir)
+			// Continue with DFS from the new position
+			push(newX, newY)
+		}
+	}
+}
+
+// PrimGenerator implements a randomized Prim's algorithm: the maze grows
+// outward from a single cell by connecting random frontier cells, which
+// gives many short dead ends
+type PrimGenerator struct{}
+
+func (PrimGenerator) Name() string { return "Prim" }
+
+func (PrimGenerator) Generate(maze *Maze, rng *rand.Rand) {
+	const (
+		outside = iota
+		frontier
+		inside
+	)
+
+	state := make([][]int, maze.Height)
+	for i := range state {
+		state[i] = make([]int, maze.Width)
+	}
+
+	var frontierCells []Position
+	mark := func(x, y int) {
+		state[y][x] = inside
+		for _, dir := range maze.Directions(x, y) {
+			nx, ny := maze.Neighbor(x, y, dir)
+			if maze.IsValidPosition(nx, ny) && state[ny][nx] == outside {
+				state[ny][nx] = frontier
+				frontierCells = append(frontierCells, Position{X: nx, Y: ny})
+			}
+		}
+	}
+
+	start := maze.randomCell(rng)
+	mark(start.X, start.Y)
+	for len(frontierCells) > 0 {
+		// Take a random frontier cell out of the list
+		i := rng.Intn(len(frontierCells))
+		cell := frontierCells[i]
+		frontierCells[i] = frontierCells[len(frontierCells)-1]
+		frontierCells = frontierCells[:len(frontierCells)-1]
+
+		// Connect it to a random neighbor that is already part of the maze
+		var candidates []MazeDirection
+		for _, dir := range maze.Directions(cell.X, cell.Y) {
+			nx, ny := maze.Neighbor(cell.X, cell.Y, dir)
+			if maze.IsValidPosition(nx, ny) && state[ny][nx] == inside {
+				candidates = append(candidates, dir)
+			}
+		}
+		maze.RemoveWall(cell.X, cell.Y, candidates[rng.Intn(len(candidates))])
+		mark(cell.X, cell.Y)
+	}
+}
+
+// KruskalGenerator implements a randomized Kruskal's algorithm: walls are
+// removed in random order whenever they separate two unconnected regions
+type KruskalGenerator struct{}
+
+func (KruskalGenerator) Name() string { return "Kruskal" }
+
+func (KruskalGenerator) Generate(maze *Maze, rng *rand.Rand) {
+	type wall struct {
+		x, y int
+		dir  MazeDirection
+	}
+
+	// Every interior wall is seen from both sides, keep the side of the
+	// cell that comes first. In a square grid those are the east and south
+	// walls
+	walls := make([]wall, 0, 2*maze.Width*maze.Height)
+	for y := 0; y < maze.Height; y++ {
+		for x := 0; x < maze.Width; x++ {
+			if !maze.IsValidPosition(x, y) {
+				continue
+			}
+			for _, dir := range maze.Directions(x, y) {
+				nx, ny := maze.Neighbor(x, y, dir)
+				if maze.IsValidPosition(nx, ny) && ny*maze.Width+nx > y*maze.Width+x {
+					walls = append(walls, wall{x, y, dir})
+				}
+			}
+		}
+	}
+	rng.Shuffle(len(walls), func(i, j int) {
+		walls[i], walls[j] = walls[j], walls[i]
+	})
+
+	sets := newDisjointSets(maze.Width * maze.Height)
+	for _, w := range walls {
+		nx, ny := maze.Neighbor(w.x, w.y, w.dir)
+		a := w.y*maze.Width + w.x
+		b := ny*maze.Width + nx
+		if sets.union(a, b) {
+			maze.RemoveWall(w.x, w.y, w.dir)
+		}
+	}
+}
+
+// disjointSets is a union-find structure over the integers [0, n)
+type disjointSets struct {
+	parent []int
+	rank   []uint8
+}
+
+func newDisjointSets(n int) *disjointSets {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &disjointSets{parent: parent, rank: make([]uint8, n)}
+}
+
+func (s *disjointSets) find(i int) int {
+	for s.parent[i] != i {
+		s.parent[i] = s.parent[s.parent[i]]
+		i = s.parent[i]
+	}
+	return i
+}
+
+// union merges the sets containing a and b, and reports whether they were
+// different sets
+func (s *disjointSets) union(a, b int) bool {
+	ra, rb := s.find(a), s.find(b)
+	if ra == rb {
+		return false
+	}
+	switch {
+	case s.rank[ra] < s.rank[rb]:
+		s.parent[ra] = rb
+	case s.rank[ra] > s.rank[rb]:
+		s.parent[rb] = ra
+	default:
+		s.parent[rb] = ra
+		s.rank[ra]++
+	}
+	return true
+}
+
+// region returns which cells can be reached from the given one without
+// crossing masked out cells, and how many there are. Random walks stay in
+// the region they start in, so on a mask split in several regions they
+// must not wait for cells of the others
+func (m *Maze) region(from Position) ([][]bool, int) {
+	in := make([][]bool, m.Height)
+	for i := range in {
+		in[i] = make([]bool, m.Width)
+	}
+	in[from.Y][from.X] = true
+	count := 0
+	queue := []Position{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		count++
+		for _, dir := range m.Directions(p.X, p.Y) {
+			nx, ny := m.Neighbor(p.X, p.Y, dir)
+			if m.IsValidPosition(nx, ny) && !in[ny][nx] {
+				in[ny][nx] = true
+				queue = append(queue, Position{X: nx, Y: ny})
+			}
+		}
+	}
+	return in, count
+}
+
+// WilsonGenerator implements Wilson's algorithm: loop-erased random walks
+// that pick every possible perfect maze with equal probability
+type WilsonGenerator struct{}
+
+func (WilsonGenerator) Name() string { return "Wilson" }
+
+func (WilsonGenerator) Generate(maze *Maze, rng *rand.Rand) {
+	inMaze := make([][]bool, maze.Height)
+	// walk remembers the last direction the current walk left each cell by;
+	// following it from the walk's start erases any loops
+	walk := make([][]MazeDirection, maze.Height)
+	for i := range inMaze {
+		inMaze[i] = make([]bool, maze.Width)
+		walk[i] = make([]MazeDirection, maze.Width)
+	}
+
+	first := maze.randomCell(rng)
+	inMaze[first.Y][first.X] = true
+	// Only the region of the first cell is carved, walks from the others
+	// would never hit the maze
+	region, remaining := maze.region(first)
+	remaining--
+
+	for remaining > 0 {
+		// Pick a random cell that is not part of the maze yet
+		start := maze.randomCell(rng)
+		startX, startY := start.X, start.Y
+		if inMaze[startY][startX] || !region[startY][startX] {
+			continue
+		}
+
+		// Walk randomly until hitting the maze
+		x, y := startX, startY
+		for !inMaze[y][x] {
+			directions := maze.Directions(x, y)
+			dir := directions[rng.Intn(len(directions))]
+			nx, ny := maze.Neighbor(x, y, dir)
+			if !maze.IsValidPosition(nx, ny) {
+				continue
+			}
+			walk[y][x] = dir
+			x, y = nx, ny
+		}
+
+		// Carve the loop-erased path into the maze
+		x, y = startX, startY
+		for !inMaze[y][x] {
+			dir := walk[y][x]
+			maze.RemoveWall(x, y, dir)
+			inMaze[y][x] = true
+			remaining--
+			x, y = maze.Neighbor(x, y, dir)
+		}
+	}
+}
+
+// AldousBroderGenerator implements the Aldous-Broder algorithm: a single
+// random walk that carves into every cell it sees for the first time. Like
+// Wilson's algorithm it is unbiased, but it can be slow on large mazes
+type AldousBroderGenerator struct{}
+
+func (AldousBroderGenerator) Name() string { return "Aldous-Broder" }
+
+func (AldousBroderGenerator) Generate(maze *Maze, rng *rand.Rand) {
+	visited := make([][]bool, maze.Height)
+	for i := range visited {
+		visited[i] = make([]bool, maze.Width)
+	}
+
+	start := maze.randomCell(rng)
+	x, y := start.X, start.Y
+	visited[y][x] = true
+	// The walk cannot leave the region it starts in
+	_, remaining := maze.region(start)
+	remaining--
+
+	for remaining > 0 {
+		directions := maze.Directions(x, y)
+		dir := directions[rng.Intn(len(directions))]
+		nx, ny := maze.Neighbor(x, y, dir)
+		if !maze.IsValidPosition(nx, ny) {
+			continue
+		}
+		if !visited[ny][nx] {
+			maze.RemoveWall(x, y, dir)
+			visited[ny][nx] = true
+			remaining--
+		}
+		x, y = nx, ny
+	}
+}
+
+// EllerGenerator implements Eller's algorithm, which builds the maze one
+// row at a time while tracking which cells of the row are already connected
+type EllerGenerator struct{}
+
+func (EllerGenerator) Name() string { return "Eller" }
+
+func (EllerGenerator) Generate(maze *Maze, rng *rand.Rand) {
+	// sets[x] identifies which connected region cell x of the current row
+	// belongs to; 0 means the cell has not been assigned one yet
+	sets := make([]int, maze.Width)
+	nextSet := 1
+
+	for y := 0; y < maze.Height; y++ {
+		lastRow := y == maze.Height-1
+
+		for x := range sets {
+			switch {
+			case !maze.IsValidPosition(x, y):
+				sets[x] = 0
+			case sets[x] == 0:
+				sets[x] = nextSet
+				nextSet++
+			}
+		}
+
+		// Randomly join adjacent cells from different sets; the last row
+		// must join all of them so that the maze ends up connected
+		for x := 0; x+1 < maze.Width; x++ {
+			if sets[x] == 0 || sets[x+1] == 0 || sets[x] == sets[x+1] || !maze.hasDirection(x, y, East) || (!lastRow && rng.Intn(2) == 0) {
+				continue
+			}
+			maze.RemoveWall(x, y, East)
+			from, to := sets[x+1], sets[x]
+			for i := range sets {
+				if sets[i] == from {
+					sets[i] = to
+				}
+			}
+		}
+
+		if lastRow {
+			break
+		}
+
+		// Every set must continue down at least once; other cells may too
+		next := make([]int, maze.Width)
+		members := make(map[int][]int)
+		var order []int
+		for x, set := range sets {
+			if set == 0 || !maze.IsValidPosition(x, y+1) || !maze.hasDirection(x, y, South) {
+				continue
+			}
+			if _, ok := members[set]; !ok {
+				order = append(order, set)
+			}
+			members[set] = append(members[set], x)
+		}
+		for _, set := range order {
+			cells := members[set]
+			rng.Shuffle(len(cells), func(i, j int) {
+				cells[i], cells[j] = cells[j], cells[i]
+			})
+			down := 1 + rng.Intn(len(cells))
+			for _, x := range cells[:down] {
+				maze.RemoveWall(x, y, South)
+				next[x] = set
+			}
+		}
+		sets = next
+	}
+}
+
+// HuntAndKillGenerator implements the hunt-and-kill algorithm: a random
+// walk that, when it gets stuck, scans for an unvisited cell next to the
+// maze and continues from there
+type HuntAndKillGenerator struct{}
+
+func (HuntAndKillGenerator) Name() string { return "Hunt-and-Kill" }
+
+func (HuntAndKillGenerator) Generate(maze *Maze, rng *rand.Rand) {
+	visited := maze.newCellGrid()
+
+	// neighbors returns the directions from (x, y) leading to cells whose
+	// visited state matches the given one
+	neighbors := func(x, y int, wantVisited bool) []MazeDirection {
+		var dirs []MazeDirection
+		for _, dir := range maze.Directions(x, y) {
+			nx, ny := maze.Neighbor(x, y, dir)
+			if maze.IsValidPosition(nx, ny) && visited[ny][nx] == wantVisited {
+				dirs = append(dirs, dir)
+			}
+		}
+		return dirs
+	}
+
+	start := maze.randomCell(rng)
+	x, y := start.X, start.Y
+	visited[y][x] = true
+	huntRow := 0
+
+	for {
+		// Kill: walk randomly through unvisited cells
+		if dirs := neighbors(x, y, false); len(dirs) > 0 {
+			dir := dirs[rng.Intn(len(dirs))]
+			maze.RemoveWall(x, y, dir)
+			x, y = maze.Neighbor(x, y, dir)
+			visited[y][x] = true
+			continue
+		}
+
+		// Hunt: find the first unvisited cell that touches the maze. Rows
+		// above huntRow are known to be fully visited
+		found := false
+		firstUnvisitedRow := -1
+		for hy := huntRow; hy < maze.Height && !found; hy++ {
+			for hx := 0; hx < maze.Width; hx++ {
+				if visited[hy][hx] {
+					continue
+				}
+				if firstUnvisitedRow < 0 {
+					firstUnvisitedRow = hy
+				}
+				if dirs := neighbors(hx, hy, true); len(dirs) > 0 {
+					maze.RemoveWall(hx, hy, dirs[rng.Intn(len(dirs))])
+					x, y = hx, hy
+					visited[y][x] = true
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return
+		}
+		huntRow = firstUnvisitedRow
+	}
+}
+
+// SidewinderGenerator implements the sidewinder algorithm: each row is
+// split into runs of east-west passages, and every run opens north once.
+// The top row is always a single long corridor
+type SidewinderGenerator struct{}
+
+func (SidewinderGenerator) Name() string { return "Sidewinder" }
+
+func (SidewinderGenerator) Generate(maze *Maze, rng *rand.Rand) {
+	for y := 0; y < maze.Height; y++ {
+		runStart := 0
+		for x := 0; x < maze.Width; x++ {
+			if !maze.IsValidPosition(x, y) {
+				runStart = x + 1
+				continue
+			}
+
+			atEasternEdge := !maze.IsValidPosition(x+1, y) || !maze.hasDirection(x, y, East)
+			closeRun := atEasternEdge || (y > 0 && rng.Intn(2) == 0)
+
+			if !closeRun {
+				maze.RemoveWall(x, y, East)
+				continue
+			}
+
+			// Open north from a random cell of the run that has a cell above
+			var candidates []int
+			for cx := runStart; cx <= x; cx++ {
+				if maze.IsValidPosition(cx, y-1) && maze.hasDirection(cx, y, North) {
+					candidates = append(candidates, cx)
+				}
+			}
+			if len(candidates) > 0 {
+				maze.RemoveWall(candidates[rng.Intn(len(candidates))], y, North)
+			}
+			runStart = x + 1
+		}
+	}
+}
+
+// BinaryTreeGenerator implements the binary tree algorithm: every cell
+// opens either north or east. It is very fast but strongly biased, with
+// open corridors along the north and east edges
+type BinaryTreeGenerator struct{}
+
+func (BinaryTreeGenerator) Name() string { return "Binary Tree" }
+
+func (BinaryTreeGenerator) Generate(maze *Maze, rng *rand.Rand) {
+	for y := 0; y < maze.Height; y++ {
+		for x := 0; x < maze.Width; x++ {
+			if !maze.IsValidPosition(x, y) {
+				continue
+			}
+			canNorth := maze.IsValidPosition(x, y-1) && maze.hasDirection(x, y, North)
+			canEast := maze.IsValidPosition(x+1, y) && maze.hasDirection(x, y, East)
+			switch {
+			case canNorth && canEast:
+				if rng.Intn(2) == 0 {
+					maze.RemoveWall(x, y, North)
+				} else {
+					maze.RemoveWall(x, y, East)
+				}
+			case canNorth:
+				maze.RemoveWall(x, y, North)
+			case canEast:
+				maze.RemoveWall(x, y, East)
+			}
+		}
+	}
+}
+
+// RecursiveDivisionGenerator implements recursive division: starting from
+// an open room, it adds walls with a single gap, splitting the room in two,
+// and repeats on each half. It gives long straight walls and a boxy look
+type RecursiveDivisionGenerator struct{}
+
+func (RecursiveDivisionGenerator) Name() string { return "Recursive Division" }
+
+func (RecursiveDivisionGenerator) Generate(maze *Maze, rng *rand.Rand) {
+	// Open up every interior wall
+	for y := 0; y < maze.Height; y++ {
+		for x := 0; x < maze.Width; x++ {
+			if !maze.IsValidPosition(x, y) {
+				continue
+			}
+			if maze.IsValidPosition(x+1, y) && maze.hasDirection(x, y, East) {
+				maze.RemoveWall(x, y, East)
+			}
+			if maze.IsValidPosition(x, y+1) && maze.hasDirection(x, y, South) {
+				maze.RemoveWall(x, y, South)
+			}
+		}
+	}
+
+	divide(maze, 0, 0, maze.Width, maze.Height, rng)
+}
+
+// divide splits the room with its top-left corner at (x, y) with a wall
+func divide(maze *Maze, x, y, width, height int, rng *rand.Rand) {
+	if width < 2 || height < 2 {
+		return
+	}
+
+	horizontal := height > width || (height == width && rng.Intn(2) == 0)
+	if horizontal {
+		// Wall along the south side of row wallY, with a gap at gapX
+		wallY := y + rng.Intn(height-1)
+		gapX := x + rng.Intn(width)
+		for wx := x; wx < x+width; wx++ {
+			if wx != gapX {
+				maze.AddWall(wx, wallY, South)
+			}
+		}
+		divide(maze, x, y, width, wallY-y+1, rng)
+		divide(maze, x, wallY+1, width, y+height-wallY-1, rng)
+	} else {
+		// Wall along the east side of column wallX, with a gap at gapY
+		wallX := x + rng.Intn(width-1)
+		gapY := y + rng.Intn(height)
+		for wy := y; wy < y+height; wy++ {
+			if wy != gapY {
+				maze.AddWall(wallX, wy, East)
+			}
+		}
+		divide(maze, x, y, wallX-x+1, height, rng)
+		divide(maze, wallX+1, y, x+width-wallX-1, height, rng)
+	}
+}
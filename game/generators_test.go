@@ -0,0 +1,250 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertPerfectMaze checks that every cell is reachable and that there are
+// no loops, i.e. the passages form a spanning tree. A crossing is not a
+// cell of the tree but two passages, each made of two open walls
+func assertPerfectMaze(t *testing.T, maze *Maze) {
+	t.Helper()
+
+	passages, crossings := 0, 0
+	for y := 0; y < maze.Height; y++ {
+		for x := 0; x < maze.Width; x++ {
+			if !maze.IsValidPosition(x, y) {
+				continue
+			}
+			if maze.isCrossing(x, y) {
+				crossings++
+			}
+			// Count each passage from the cell that comes first
+			for _, dir := range maze.Directions(x, y) {
+				nx, ny := maze.Neighbor(x, y, dir)
+				if maze.IsValidPosition(nx, ny) && ny*maze.Width+nx > y*maze.Width+x && !maze.HasWall(x, y, dir) {
+					passages++
+				}
+			}
+		}
+	}
+	assert.Equal(t, maze.cellCount()-1+crossings, passages, "a perfect maze has one passage less than it has cells")
+
+	reached := 0
+	for _, row := range maze.DistanceMap(maze.firstCell()) {
+		for _, d := range row {
+			if d >= 0 {
+				reached++
+			}
+		}
+	}
+	assert.Equal(t, maze.cellCount()-crossings, reached, "every cell should be reachable")
+}
+
+func TestGenerators(t *testing.T) {
+	sizes := []struct{ width, height int }{
+		{1, 1},
+		{1, 7},
+		{7, 1},
+		{2, 2},
+		{10, 10},
+		{23, 17},
+	}
+
+	for a := 0; a < mazeAlgorithmCount; a++ {
+		algorithm := MazeAlgorithm(a)
+		t.Run(algorithm.String(), func(t *testing.T) {
+			for _, size := range sizes {
+				for seed := int64(0); seed < 5; seed++ {
+					maze := NewMaze(size.width, size.height)
+					algorithm.Generator().Generate(maze, rand.New(rand.NewSource(seed)))
+					assertPerfectMaze(t, maze)
+				}
+			}
+		})
+	}
+}
+
+func TestMazeAlgorithmByName(t *testing.T) {
+	for a := 0; a < mazeAlgorithmCount; a++ {
+		algorithm, ok := MazeAlgorithmByName(MazeAlgorithm(a).String())
+		assert.True(t, ok)
+		assert.Equal(t, MazeAlgorithm(a), algorithm)
+	}
+	algorithm, ok := MazeAlgorithmByName("binarytree")
+	assert.True(t, ok)
+	assert.Equal(t, AlgorithmBinaryTree, algorithm)
+	algorithm, ok = MazeAlgorithmByName("HUNT-AND-KILL")
+	assert.True(t, ok)
+	assert.Equal(t, AlgorithmHuntAndKill, algorithm)
+	_, ok = MazeAlgorithmByName("Bogosort")
+	assert.False(t, ok)
+}
+
+func TestGeneratorsOnGrids(t *testing.T) {
+	for g := 0; g < gridCount; g++ {
+		grid := Grid(g)
+		for a := 0; a < mazeAlgorithmCount; a++ {
+			algorithm := MazeAlgorithm(a)
+			t.Run(grid.String()+"/"+algorithm.String(), func(t *testing.T) {
+				for seed := int64(0); seed < 5; seed++ {
+					maze, start := MazeConfig{
+						Width:     9,
+						Height:    7,
+						Seed:      seed,
+						Generator: algorithm.Generator(),
+						Grid:      grid,
+					}.Generate()
+					assert.Equal(t, grid.String(), maze.Geometry().Name())
+					assertPerfectMaze(t, maze)
+					_, ok := maze.ShortestPath(start)
+					assert.True(t, ok, "the exit should be reachable")
+				}
+			})
+		}
+	}
+}
+
+func TestGeneratorsOnSplitMask(t *testing.T) {
+	// Generators called directly get masks that MazeConfig would have cut
+	// down to their largest region
+	mask, err := ParseMask("##.###\n##.###\n##.###")
+	require.NoError(t, err)
+
+	for a := 0; a < mazeAlgorithmCount; a++ {
+		algorithm := MazeAlgorithm(a)
+		t.Run(algorithm.String(), func(t *testing.T) {
+			for seed := int64(0); seed < 5; seed++ {
+				maze := NewMaze(mask.Width, mask.Height)
+				maze.Mask = mask
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					algorithm.Generator().Generate(maze, rand.New(rand.NewSource(seed)))
+				}()
+				select {
+				case <-done:
+				case <-time.After(5 * time.Second):
+					t.Fatalf("seed %d: generating did not finish", seed)
+				}
+			}
+		})
+	}
+
+	// Random walks carve the whole region they start in
+	for _, generator := range []Generator{WilsonGenerator{}, AldousBroderGenerator{}} {
+		for seed := int64(0); seed < 5; seed++ {
+			maze := NewMaze(mask.Width, mask.Height)
+			maze.Mask = mask
+			generator.Generate(maze, rand.New(rand.NewSource(seed)))
+			// A carved cell has an open wall, so a walled in corner tells
+			// the walk started on the right
+			from := maze.firstCell()
+			if maze.HasWall(0, 0, East) && maze.HasWall(0, 0, South) {
+				from = Position{3, 0}
+			}
+			_, size := maze.region(from)
+			reached := 0
+			for _, row := range maze.DistanceMap(from) {
+				for _, d := range row {
+					if d >= 0 {
+						reached++
+					}
+				}
+			}
+			assert.Equal(t, size, reached, "%s seed %d", generator.Name(), seed)
+		}
+	}
+}
+
+func TestMazeConfigGenerate(t *testing.T) {
+	for a := 0; a < mazeAlgorithmCount; a++ {
+		algorithm := MazeAlgorithm(a)
+		t.Run(algorithm.String(), func(t *testing.T) {
+			config := MazeConfig{Width: 12, Height: 9, Seed: 7, Generator: algorithm.Generator()}
+			maze1, start1 := config.Generate()
+			maze2, start2 := config.Generate()
+			assert.Equal(t, maze1.String(), maze2.String(), "the same config should give the same maze")
+			assert.Equal(t, start1, start2, "the same config should give the same start position")
+		})
+	}
+}
+
+// generateMazeDFSRecursive is the original recursive backtracker, kept as a
+// reference for generateMazeDFS
+func generateMazeDFSRecursive(maze *Maze, visited [][]bool, x, y int, rng *rand.Rand) {
+	visited[y][x] = true
+
+	directions := []MazeDirection{North, East, South, West}
+	for i := len(directions) - 1; i > 0; i-- {
+		j := rng.Intn(i + 1)
+		directions[i], directions[j] = directions[j], directions[i]
+	}
+
+	for _, dir := range directions {
+		dx, dy := dir.Offset()
+		newX, newY := x+dx, y+dy
+		if maze.IsValidPosition(newX, newY) && !visited[newY][newX] {
+			maze.RemoveWall(x, y, dir)
+			generateMazeDFSRecursive(maze, visited, newX, newY, rng)
+		}
+	}
+}
+
+func newVisited(width, height int) [][]bool {
+	visited := make([][]bool, height)
+	for i := range visited {
+		visited[i] = make([]bool, width)
+	}
+	return visited
+}
+
+func TestGenerateMazeDFSMatchesRecursive(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		width, height := 5+int(seed), 30-int(seed)
+
+		iterative := NewMaze(width, height)
+		generateMazeDFS(iterative, 2, 3, rand.New(rand.NewSource(seed)))
+
+		recursive := NewMaze(width, height)
+		generateMazeDFSRecursive(recursive, newVisited(width, height), 2, 3, rand.New(rand.NewSource(seed)))
+
+		assert.Equal(t, recursive.String(), iterative.String(), "seed %d should give the same maze", seed)
+	}
+}
+
+// BenchmarkGenerateMazeDFS compares both backtrackers. Note that B/op does
+// not include the goroutine stack the recursive version grows into
+func BenchmarkGenerateMazeDFS(b *testing.B) {
+	for _, size := range []int{100, 1000} {
+		b.Run(fmt.Sprintf("recursive/%dx%d", size, size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				maze := NewMaze(size, size)
+				generateMazeDFSRecursive(maze, newVisited(size, size), 0, 0, rand.New(rand.NewSource(int64(i))))
+			}
+		})
+		b.Run(fmt.Sprintf("iterative/%dx%d", size, size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				maze := NewMaze(size, size)
+				generateMazeDFS(maze, 0, 0, rand.New(rand.NewSource(int64(i))))
+			}
+		})
+	}
+
+	// Too deep for the recursive version to be practical
+	b.Run("iterative/2000x2000", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			maze := NewMaze(2000, 2000)
+			generateMazeDFS(maze, 0, 0, rand.New(rand.NewSource(int64(i))))
+		}
+	})
+}
@@ -0,0 +1,337 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// mazeJSON is the JSON form of a Maze
+type mazeJSON struct {
+	Width     int           `json:"width"`
+	Height    int           `json:"height"`
+	Grid      string        `json:"grid,omitempty"`
+	Seed      int64         `json:"seed,omitempty"`
+	Generator string        `json:"generator,omitempty"`
+	Start     Position      `json:"start"`
+	Exit      *exitJSON     `json:"exit,omitempty"`
+	Goal      *Position     `json:"goal,omitempty"`
+	Cells     [][]*cellJSON `json:"cells"`
+}
+
+type exitJSON struct {
+	X         int           `json:"x"`
+	Y         int           `json:"y"`
+	Direction MazeDirection `json:"direction"`
+}
+
+type cellJSON struct {
+	Open     []MazeDirection `json:"open,omitempty"`
+	Crossing string          `json:"crossing,omitempty"`
+}
+
+// maxJSONDimension bounds the width and height UnmarshalJSON accepts
+const maxJSONDimension = 1 << 12
+
+// MarshalJSON encodes the maze as a JSON object of this form:
+//
+//	{
+//	  "width": 3,                  // number of columns, at least 1
+//	  "height": 2,                 // number of rows, at least 1
+//	  "grid": "Square",            // topology name, see Grid; default Square
+//	  "seed": 42,                  // optional, the seed it was generated from
+//	  "generator": "Backtracker",  // optional, the algorithm that carved it
+//	  "start": {"x": 0, "y": 0},   // where the player begins
+//	  "exit": {"x": 2, "y": 1, "direction": "East"}, // optional
+//	  "goal": {"x": 1, "y": 1},    // optional, for mazes without outer wall
+//	  "cells": [                   // height rows of width cells each
+//	    [{"open": ["East"]}, {"open": ["West", "South"]}, null],
+//	    ...
+//	  ]
+//	}
+//
+// Each cell lists the directions in which it has no wall under "open", by
+// their MazeDirection names; every other side of the cell is walled. An
+// open Up or Down is a staircase. Cells that are masked out of the maze are
+// null. A crossing of a weave maze has "crossing": "North-South" or
+// "East-West", carrying that passage over the other.
+//
+// Loading checks that every open direction exists for the cell, that the
+// cells on both sides of every wall agree on it, and that the start, exit
+// and goal are cells of the maze. The exit must lead out of the maze
+// through an open side. Only the built-in topologies can be encoded
+func (m *Maze) MarshalJSON() ([]byte, error) {
+	topology := m.Geometry()
+	if _, ok := gridByName(topology.Name()); !ok {
+		return nil, fmt.Errorf("cannot encode maze: unknown topology %q", topology.Name())
+	}
+
+	v := mazeJSON{
+		Width:     m.Width,
+		Height:    m.Height,
+		Grid:      topology.Name(),
+		Seed:      m.Seed,
+		Generator: m.Generator,
+		Start:     m.Start,
+		Goal:      m.Goal,
+		Cells:     make([][]*cellJSON, m.Height),
+	}
+	if m.Exit != nil {
+		v.Exit = &exitJSON{X: m.Exit.Position.X, Y: m.Exit.Position.Y, Direction: m.Exit.Direction}
+	}
+	for y := range v.Cells {
+		v.Cells[y] = make([]*cellJSON, m.Width)
+		for x := range v.Cells[y] {
+			if !m.IsValidPosition(x, y) {
+				continue // Masked out cells stay null
+			}
+			cell := &cellJSON{}
+			for _, directions := range [][]MazeDirection{m.Directions(x, y), {Up, Down}} {
+				for _, dir := range directions {
+					if !m.HasWall(x, y, dir) {
+						cell.Open = append(cell.Open, dir)
+					}
+				}
+			}
+			if crossing := m.Crossing(x, y); crossing != NoCrossing {
+				cell.Crossing = crossing.String()
+			}
+			v.Cells[y][x] = cell
+		}
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a maze from the JSON form described in
+// MarshalJSON, replacing the contents of m. It rejects mazes whose walls do not match up
+// or whose start, exit or goal lie outside the maze
+func (m *Maze) UnmarshalJSON(data []byte) error {
+	var v mazeJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Width <= 0 || v.Height <= 0 || v.Width > maxJSONDimension || v.Height > maxJSONDimension {
+		return fmt.Errorf("invalid maze JSON: invalid dimensions: width=%d, height=%d", v.Width, v.Height)
+	}
+	if len(v.Cells) != v.Height {
+		return fmt.Errorf("invalid maze JSON: %d rows of cells, want %d", len(v.Cells), v.Height)
+	}
+	grid := GridSquare
+	if v.Grid != "" {
+		var ok bool
+		if grid, ok = gridByName(v.Grid); !ok {
+			return fmt.Errorf("invalid maze JSON: unknown grid %q", v.Grid)
+		}
+	}
+
+	maze := NewMaze(v.Width, v.Height)
+	if grid != GridSquare {
+		maze.Topology = grid.Topology(v.Width, v.Height)
+	}
+	maze.Seed = v.Seed
+	maze.Generator = v.Generator
+
+	// Masked out cells first, so walls towards them count as outer walls
+	mask := NewMask(v.Width, v.Height)
+	for y, row := range v.Cells {
+		if len(row) != v.Width {
+			return fmt.Errorf("invalid maze JSON: row %d has %d cells, want %d", y, len(row), v.Width)
+		}
+		for x, cell := range row {
+			mask.Set(x, y, cell != nil)
+		}
+	}
+	if mask.Count() < v.Width*v.Height {
+		maze.Mask = mask
+	}
+
+	for y, row := range v.Cells {
+		for x, cell := range row {
+			if cell == nil {
+				continue
+			}
+			for _, dir := range cell.Open {
+				if dir != Up && dir != Down && !maze.hasDirection(x, y, dir) {
+					return fmt.Errorf("invalid maze JSON: cell (%d, %d) has no %s side", x, y, dir)
+				}
+				maze.RemoveWall(x, y, dir)
+			}
+			if cell.Crossing != "" {
+				crossing, ok := crossingByName(cell.Crossing)
+				if !ok {
+					return fmt.Errorf("invalid maze JSON: cell (%d, %d) has unknown crossing %q", x, y, cell.Crossing)
+				}
+				maze.SetCrossing(x, y, crossing)
+			}
+		}
+	}
+
+	// Walls are shared, so every open wall must be listed as open by the
+	// cells on both sides
+	for y, row := range v.Cells {
+		for x, cell := range row {
+			if cell == nil {
+				continue
+			}
+			for _, dir := range maze.Directions(x, y) {
+				nx, ny := maze.Neighbor(x, y, dir)
+				if !maze.IsValidPosition(nx, ny) || maze.HasWall(x, y, dir) {
+					continue
+				}
+				if !containsDirection(cell.Open, dir) || !containsDirection(v.Cells[ny][nx].Open, dir.Opposite()) {
+					return fmt.Errorf("invalid maze JSON: wall between (%d, %d) and (%d, %d) is open on one side only", x, y, nx, ny)
+				}
+			}
+		}
+	}
+
+	if err := maze.checkCrossings(); err != nil {
+		return fmt.Errorf("invalid maze JSON: %w", err)
+	}
+
+	maze.Start = v.Start
+	if !maze.IsValidPosition(v.Start.X, v.Start.Y) {
+		return fmt.Errorf("invalid maze JSON: start %v is outside the maze", v.Start)
+	}
+	if v.Exit != nil {
+		exit := Exit{Position: Position{X: v.Exit.X, Y: v.Exit.Y}, Direction: v.Exit.Direction}
+		if !maze.IsValidPosition(exit.Position.X, exit.Position.Y) {
+			return fmt.Errorf("invalid maze JSON: exit %v is outside the maze", exit.Position)
+		}
+		next, ok := maze.Move(exit.Position, exit.Direction)
+		if !maze.hasDirection(exit.Position.X, exit.Position.Y, exit.Direction) || !ok || maze.IsValidPosition(next.X, next.Y) {
+			return fmt.Errorf("invalid maze JSON: exit at %v does not lead %s out of the maze", exit.Position, exit.Direction)
+		}
+		maze.Exit = &exit
+	}
+	if v.Goal != nil {
+		if !maze.IsValidPosition(v.Goal.X, v.Goal.Y) {
+			return fmt.Errorf("invalid maze JSON: goal %v is outside the maze", *v.Goal)
+		}
+		goal := *v.Goal
+		maze.Goal = &goal
+	}
+
+	*m = *maze
+	return nil
+}
+
+func containsDirection(directions []MazeDirection, direction MazeDirection) bool {
+	for _, d := range directions {
+		if d == direction {
+			return true
+		}
+	}
+	return false
+}
+
+// crossingByName returns the crossing with the given name, see
+// Crossing.String
+func crossingByName(name string) (Crossing, bool) {
+	for _, c := range [...]Crossing{NoCrossing, CrossingNorthSouth, CrossingEastWest} {
+		if c.String() == name {
+			return c, true
+		}
+	}
+	return NoCrossing, false
+}
+
+// positionJSON is the JSON form of a Position
+type positionJSON struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// MarshalJSON encodes the position as {"x": 1, "y": 2}
+func (p Position) MarshalJSON() ([]byte, error) {
+	return json.Marshal(positionJSON(p))
+}
+
+// UnmarshalJSON decodes a position encoded by MarshalJSON
+func (p *Position) UnmarshalJSON(data []byte) error {
+	var v positionJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = Position(v)
+	return nil
+}
+
+// locationJSON is the JSON form of a Location. Location embeds Position,
+// so without methods of its own it would be encoded as a bare position
+type locationJSON struct {
+	Floor int `json:"floor"`
+	X     int `json:"x"`
+	Y     int `json:"y"`
+}
+
+// MarshalJSON encodes the location as {"floor": 0, "x": 1, "y": 2}
+func (l Location) MarshalJSON() ([]byte, error) {
+	return json.Marshal(locationJSON{Floor: l.Floor, X: l.X, Y: l.Y})
+}
+
+// UnmarshalJSON decodes a location encoded by MarshalJSON
+func (l *Location) UnmarshalJSON(data []byte) error {
+	var v locationJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*l = Location{Floor: v.Floor, Position: Position{X: v.X, Y: v.Y}}
+	return nil
+}
+
+// MarshalJSON encodes the direction by name, as in "NorthEast"
+func (d MazeDirection) MarshalJSON() ([]byte, error) {
+	return marshalEnumJSON("maze direction", int(d), maxDirections, d.String())
+}
+
+// UnmarshalJSON decodes a direction encoded by MarshalJSON
+func (d *MazeDirection) UnmarshalJSON(data []byte) error {
+	return unmarshalEnumJSON(data, "maze direction", maxDirections, func(i int) string { return MazeDirection(i).String() },
+		func(i int) { *d = MazeDirection(i) })
+}
+
+// MarshalJSON encodes the speed by name, as in "Medium"
+func (s PlayerSpeed) MarshalJSON() ([]byte, error) {
+	return marshalEnumJSON("player speed", int(s), playerSpeedCount, s.String())
+}
+
+// UnmarshalJSON decodes a speed encoded by MarshalJSON
+func (s *PlayerSpeed) UnmarshalJSON(data []byte) error {
+	return unmarshalEnumJSON(data, "player speed", playerSpeedCount, func(i int) string { return PlayerSpeed(i).String() },
+		func(i int) { *s = PlayerSpeed(i) })
+}
+
+// MarshalJSON encodes the size by name, as in "Big"
+func (s MazeSize) MarshalJSON() ([]byte, error) {
+	return marshalEnumJSON("maze size", int(s), mazeSizeCount, s.String())
+}
+
+// UnmarshalJSON decodes a size encoded by MarshalJSON
+func (s *MazeSize) UnmarshalJSON(data []byte) error {
+	return unmarshalEnumJSON(data, "maze size", mazeSizeCount, func(i int) string { return MazeSize(i).String() },
+		func(i int) { *s = MazeSize(i) })
+}
+
+// marshalEnumJSON encodes the value of an enum with count values by name
+func marshalEnumJSON(kind string, value, count int, name string) ([]byte, error) {
+	if value < 0 || value >= count {
+		return nil, fmt.Errorf("cannot encode %s %d", kind, value)
+	}
+	return json.Marshal(name)
+}
+
+// unmarshalEnumJSON decodes the name of one of the count values of an
+// enum, and passes the value to set
+func unmarshalEnumJSON(data []byte, kind string, count int, name func(int) string, set func(int)) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid %s: %w", kind, err)
+	}
+	for i := 0; i < count; i++ {
+		if name(i) == s {
+			set(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown %s %q", kind, s)
+}
@@ -0,0 +1,155 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMazeJSONRoundTrip(t *testing.T) {
+	mask, err := ParseMask(ringMask)
+	require.NoError(t, err)
+
+	configs := map[string]MazeConfig{
+		"square": {Width: 12, Height: 9, Seed: 3},
+		"weave":  {Width: 12, Height: 9, Seed: 4, Generator: WeaveGenerator{}},
+		"masked": {Mask: mask, Seed: 5, Generator: PrimGenerator{}},
+	}
+	for g := 1; g < gridCount; g++ {
+		configs[Grid(g).String()] = MazeConfig{Width: 8, Height: 6, Seed: int64(g), Grid: Grid(g)}
+	}
+
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			maze, _ := config.Generate()
+			data, err := json.Marshal(maze)
+			require.NoError(t, err)
+
+			var decoded Maze
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assertSameMaze(t, maze, &decoded)
+		})
+	}
+
+	// Stairs survive too
+	tower := MazeConfig{Width: 4, Height: 4, Seed: 1, Floors: 2}.GenerateTower()
+	data, err := json.Marshal(tower.Floors[0])
+	require.NoError(t, err)
+	var floor Maze
+	require.NoError(t, json.Unmarshal(data, &floor))
+	assertSameMaze(t, tower.Floors[0], &floor)
+}
+
+func TestMazeJSON(t *testing.T) {
+	const valid = `{
+		"width": 2, "height": 2,
+		"start": {"x": 0, "y": 0},
+		"exit": {"x": 1, "y": 1, "direction": "East"},
+		"cells": [
+			[{"open": ["East"]}, {"open": ["West", "South"]}],
+			[null, {"open": ["North", "East"]}]
+		]
+	}`
+	var maze Maze
+	require.NoError(t, json.Unmarshal([]byte(valid), &maze))
+	assert.Equal(t, "Square", maze.Geometry().Name())
+	assert.Equal(t, &Exit{Position: Position{1, 1}, Direction: East}, maze.Exit)
+	assert.False(t, maze.IsValidPosition(0, 1), "null cells are masked out")
+	path, ok := maze.ShortestPath(maze.Start)
+	require.True(t, ok)
+	assert.Equal(t, []MazeDirection{East, South, East}, path.Directions)
+
+	invalid := map[string]string{
+		"asymmetric wall": `{"width": 2, "height": 1, "start": {"x": 0, "y": 0},
+			"cells": [[{"open": ["East"]}, {}]]}`,
+		"unknown direction": `{"width": 1, "height": 1, "start": {"x": 0, "y": 0},
+			"cells": [[{"open": ["Northward"]}]]}`,
+		"direction the cell lacks": `{"width": 2, "height": 1, "grid": "Hex", "start": {"x": 0, "y": 0},
+			"cells": [[{"open": ["East"]}, {"open": ["West"]}]]}`,
+		"start out of bounds": `{"width": 1, "height": 1, "start": {"x": 1, "y": 0}, "cells": [[{}]]}`,
+		"start masked out":    `{"width": 2, "height": 1, "start": {"x": 1, "y": 0}, "cells": [[{}, null]]}`,
+		"goal out of bounds": `{"width": 1, "height": 1, "start": {"x": 0, "y": 0}, "goal": {"x": 0, "y": -1},
+			"cells": [[{}]]}`,
+		"exit behind a wall": `{"width": 1, "height": 1, "start": {"x": 0, "y": 0},
+			"exit": {"x": 0, "y": 0, "direction": "West"}, "cells": [[{}]]}`,
+		"exit inside the maze": `{"width": 2, "height": 1, "start": {"x": 0, "y": 0},
+			"exit": {"x": 0, "y": 0, "direction": "East"}, "cells": [[{"open": ["East"]}, {"open": ["West"]}]]}`,
+		"missing row":  `{"width": 1, "height": 2, "start": {"x": 0, "y": 0}, "cells": [[{}]]}`,
+		"short row":    `{"width": 2, "height": 1, "start": {"x": 0, "y": 0}, "cells": [[{}]]}`,
+		"no cells":     `{"width": 0, "height": 0, "start": {"x": 0, "y": 0}, "cells": []}`,
+		"unknown grid": `{"width": 1, "height": 1, "grid": "Cube", "start": {"x": 0, "y": 0}, "cells": [[{}]]}`,
+		"bad crossing": `{"width": 1, "height": 1, "start": {"x": 0, "y": 0}, "cells": [[{"crossing": "Up-Down"}]]}`,
+		// Moves along the second row would wrap round it forever
+		"crossings round a torus": `{"width": 3, "height": 2, "grid": "Torus", "start": {"x": 0, "y": 0},
+			"cells": [[{}, {}, {}], [
+				{"open": ["East", "West"], "crossing": "East-West"},
+				{"open": ["East", "West"], "crossing": "East-West"},
+				{"open": ["East", "West"], "crossing": "East-West"}]]}`,
+		"adjacent crossings": `{"width": 4, "height": 3, "start": {"x": 0, "y": 0},
+			"cells": [[{}, {"open": ["South"]}, {"open": ["South"]}, {}], [
+				{"open": ["East"]},
+				{"open": ["North", "South", "East", "West"], "crossing": "North-South"},
+				{"open": ["North", "South", "East", "West"], "crossing": "North-South"},
+				{"open": ["West"]}], [{}, {"open": ["North"]}, {"open": ["North"]}, {}]]}`,
+		"crossing on the edge": `{"width": 2, "height": 1, "start": {"x": 0, "y": 0},
+			"cells": [[{"open": ["East"], "crossing": "East-West"}, {"open": ["West"]}]]}`,
+		"walled crossing": `{"width": 3, "height": 3, "start": {"x": 0, "y": 0},
+			"cells": [[{}, {"open": ["South"]}, {}], [
+				{},
+				{"open": ["North", "South", "East"], "crossing": "North-South"},
+				{"open": ["West"]}], [{}, {"open": ["North"]}, {}]]}`,
+		"not an object":  `[1, 2]`,
+		"not even JSON":  `{"width": `,
+		"wrong position": `{"width": 1, "height": 1, "start": [0, 0], "cells": [[{}]]}`,
+	}
+	for name, data := range invalid {
+		t.Run(name, func(t *testing.T) {
+			var maze Maze
+			assert.Error(t, json.Unmarshal([]byte(data), &maze))
+		})
+	}
+}
+
+func TestEnumJSON(t *testing.T) {
+	for d := 0; d < maxDirections; d++ {
+		data, err := json.Marshal(MazeDirection(d))
+		require.NoError(t, err)
+		assert.Equal(t, `"`+MazeDirection(d).String()+`"`, string(data))
+		var decoded MazeDirection
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, MazeDirection(d), decoded)
+	}
+
+	var settings struct {
+		Speed PlayerSpeed `json:"speed"`
+		Size  MazeSize    `json:"size"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"speed": "High", "size": "Small"}`), &settings))
+	assert.Equal(t, SpeedHigh, settings.Speed)
+	assert.Equal(t, SizeSmall, settings.Size)
+	data, err := json.Marshal(settings)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"speed": "High", "size": "Small"}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"speed": "Ludicrous"}`), &settings))
+	assert.Error(t, json.Unmarshal([]byte(`{"size": 2}`), &settings))
+	_, err = json.Marshal(MazeDirection(-1))
+	assert.Error(t, err)
+	_, err = json.Marshal(PlayerSpeed(7))
+	assert.Error(t, err)
+}
+
+func TestPositionJSON(t *testing.T) {
+	data, err := json.Marshal(Position{X: 3, Y: 4})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"x": 3, "y": 4}`, string(data))
+
+	data, err = json.Marshal(Location{Floor: 1, Position: Position{X: 3, Y: 4}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"floor": 1, "x": 3, "y": 4}`, string(data))
+	var location Location
+	require.NoError(t, json.Unmarshal(data, &location))
+	assert.Equal(t, Location{Floor: 1, Position: Position{X: 3, Y: 4}}, location)
+}
@@ -0,0 +1,136 @@
+package game
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// levelPackManifest is the name of the file describing a level pack
+const levelPackManifest = "pack.json"
+
+// builtinLevels holds the level packs shipped with the game, one directory
+// per pack
+//
+//go:embed levels
+var builtinLevels embed.FS
+
+// Level is a handcrafted maze, with how it is meant to be played
+type Level struct {
+	Name string
+	// Speed is how fast the player's direction turns
+	Speed PlayerSpeed
+	// Par is the time a good run takes, or zero if the level has none
+	Par  time.Duration
+	Maze *Maze
+}
+
+// LevelPack is a set of levels meant to be played in order
+type LevelPack struct {
+	Name   string
+	Levels []Level
+}
+
+// levelPackJSON is the manifest of a level pack. Levels without a speed
+// are played at SpeedMedium, and par times are written as in "1m30s"
+type levelPackJSON struct {
+	Name   string `json:"name"`
+	Levels []struct {
+		File  string       `json:"file"`
+		Name  string       `json:"name"`
+		Speed *PlayerSpeed `json:"speed"`
+		Par   string       `json:"par"`
+	} `json:"levels"`
+}
+
+// LoadLevelPack reads the level pack in the given directory of fsys: a
+// pack.json manifest naming the pack and listing its levels in order, next
+// to one file per level in the format ParseMaze reads. Mazes are parsed
+// strictly, but line endings and trailing whitespace are forgiven, and
+// mazes that cannot be played are refused
+func LoadLevelPack(fsys fs.FS, dir string) (*LevelPack, error) {
+	data, err := fs.ReadFile(fsys, path.Join(dir, levelPackManifest))
+	if err != nil {
+		return nil, err
+	}
+	var manifest levelPackJSON
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", path.Join(dir, levelPackManifest), err)
+	}
+	if manifest.Name == "" {
+		return nil, fmt.Errorf("%s: level pack has no name", path.Join(dir, levelPackManifest))
+	}
+	if len(manifest.Levels) == 0 {
+		return nil, fmt.Errorf("%s: level pack has no levels", path.Join(dir, levelPackManifest))
+	}
+
+	pack := &LevelPack{Name: manifest.Name}
+	for _, l := range manifest.Levels {
+		file := path.Join(dir, l.File)
+		level := Level{Name: l.Name, Speed: SpeedMedium}
+		if level.Name == "" {
+			level.Name = l.File
+		}
+		if l.Speed != nil {
+			level.Speed = *l.Speed
+		}
+		if l.Par != "" {
+			if level.Par, err = time.ParseDuration(l.Par); err != nil || level.Par < 0 {
+				return nil, fmt.Errorf("%s: invalid par time %q for %s", path.Join(dir, levelPackManifest), l.Par, l.File)
+			}
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		if level.Maze, err = (ParseOptions{Strict: true, Lenient: true}).Parse(string(data)); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if err := level.Maze.Validate(level.Maze.Start).Err(); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		pack.Levels = append(pack.Levels, level)
+	}
+	return pack, nil
+}
+
+// LoadLevelPacks reads every level pack in the top directories of fsys,
+// in order of directory name. Directories without a manifest are skipped.
+// Packs on disk are loaded with os.DirFS
+func LoadLevelPacks(fsys fs.FS) ([]*LevelPack, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var packs []*LevelPack
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := fs.Stat(fsys, path.Join(entry.Name(), levelPackManifest)); err != nil {
+			continue
+		}
+		pack, err := LoadLevelPack(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, pack)
+	}
+	return packs, nil
+}
+
+// BuiltinLevelPacks returns the level packs shipped with the game
+func BuiltinLevelPacks() ([]*LevelPack, error) {
+	levels, err := fs.Sub(builtinLevels, "levels")
+	if err != nil {
+		return nil, err
+	}
+	return LoadLevelPacks(levels)
+}
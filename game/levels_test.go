@@ -0,0 +1,97 @@
+package game
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinLevelPacks(t *testing.T) {
+	packs, err := BuiltinLevelPacks()
+	require.NoError(t, err)
+	require.Len(t, packs, 2)
+	assert.Equal(t, "Tutorial", packs[0].Name, "packs come in order of directory name")
+	assert.Equal(t, "Classics", packs[1].Name)
+
+	first := packs[0].Levels[0]
+	assert.Equal(t, "First Steps", first.Name)
+	assert.Equal(t, SpeedLow, first.Speed)
+	assert.Equal(t, 10*time.Second, first.Par)
+	assert.Equal(t, Position{0, 0}, first.Maze.Start)
+
+	for _, pack := range packs {
+		for _, level := range pack.Levels {
+			assert.NoError(t, level.Maze.Validate(level.Maze.Start).Err(), "%s: %s", pack.Name, level.Name)
+		}
+	}
+}
+
+func TestLoadLevelPacks(t *testing.T) {
+	const maze = "+--+--+\n|S   E  \n+--+--+\n"
+	fsys := fstest.MapFS{
+		"b/pack.json": {Data: []byte(`{"name": "B", "levels": [
+			{"file": "one.txt", "name": "One", "speed": "High", "par": "1m30s"},
+			{"file": "two.txt"}
+		]}`)},
+		"b/one.txt":    {Data: []byte(maze)},
+		"b/two.txt":    {Data: []byte("+--+--+\r\n|S   E  \r\n+--+--+\r\n")},
+		"a/pack.json":  {Data: []byte(`{"name": "A", "levels": [{"file": "one.txt"}]}`)},
+		"a/one.txt":    {Data: []byte(maze)},
+		"notes/readme": {Data: []byte("not a pack")},
+	}
+	packs, err := LoadLevelPacks(fsys)
+	require.NoError(t, err)
+	require.Len(t, packs, 2)
+	assert.Equal(t, "A", packs[0].Name)
+
+	b := packs[1]
+	require.Len(t, b.Levels, 2)
+	assert.Equal(t, "One", b.Levels[0].Name)
+	assert.Equal(t, SpeedHigh, b.Levels[0].Speed)
+	assert.Equal(t, 90*time.Second, b.Levels[0].Par)
+	assert.Equal(t, "two.txt", b.Levels[1].Name, "levels without a name go by their file")
+	assert.Equal(t, SpeedMedium, b.Levels[1].Speed)
+	assert.Zero(t, b.Levels[1].Par)
+	assertSameMaze(t, b.Levels[0].Maze, b.Levels[1].Maze)
+
+	invalid := map[string]fstest.MapFS{
+		"missing level": {
+			"p/pack.json": {Data: []byte(`{"name": "P", "levels": [{"file": "gone.txt"}]}`)},
+		},
+		"bad manifest": {
+			"p/pack.json": {Data: []byte(`{"name": "P", "levels": `)},
+		},
+		"no name": {
+			"p/pack.json": {Data: []byte(`{"levels": [{"file": "one.txt"}]}`)},
+			"p/one.txt":   {Data: []byte(maze)},
+		},
+		"no levels": {
+			"p/pack.json": {Data: []byte(`{"name": "P", "levels": []}`)},
+		},
+		"bad speed": {
+			"p/pack.json": {Data: []byte(`{"name": "P", "levels": [{"file": "one.txt", "speed": "Warp"}]}`)},
+			"p/one.txt":   {Data: []byte(maze)},
+		},
+		"bad par": {
+			"p/pack.json": {Data: []byte(`{"name": "P", "levels": [{"file": "one.txt", "par": "soon"}]}`)},
+			"p/one.txt":   {Data: []byte(maze)},
+		},
+		"malformed maze": {
+			"p/pack.json": {Data: []byte(`{"name": "P", "levels": [{"file": "one.txt"}]}`)},
+			"p/one.txt":   {Data: []byte("+--+-*+\n|S   E  \n+--+--+\n")},
+		},
+		"unsolvable maze": {
+			"p/pack.json": {Data: []byte(`{"name": "P", "levels": [{"file": "one.txt"}]}`)},
+			"p/one.txt":   {Data: []byte("+--+--+\n|S |  |\n+--+--+\n")},
+		},
+	}
+	for name, fsys := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadLevelPacks(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
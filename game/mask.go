@@ -0,0 +1,339 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// Mask marks which cells of a Width x Height grid belong to the maze, so
+// that mazes can take any shape. Generators expect the enabled cells to be
+// connected to each other
+type Mask struct {
+	Width   int
+	Height  int
+	enabled []bool
+}
+
+// NewMask creates a mask with every cell enabled
+func NewMask(width, height int) *Mask {
+	enabled := make([]bool, width*height)
+	for i := range enabled {
+		enabled[i] = true
+	}
+	return &Mask{Width: width, Height: height, enabled: enabled}
+}
+
+// Enabled reports whether the cell is part of the maze. Cells outside the
+// mask are never enabled
+func (m *Mask) Enabled(x, y int) bool {
+	return x >= 0 && x < m.Width && y >= 0 && y < m.Height && m.enabled[y*m.Width+x]
+}
+
+// Set enables or disables a cell
+func (m *Mask) Set(x, y int, enabled bool) {
+	if x >= 0 && x < m.Width && y >= 0 && y < m.Height {
+		m.enabled[y*m.Width+x] = enabled
+	}
+}
+
+// Count returns the number of enabled cells
+func (m *Mask) Count() int {
+	count := 0
+	for _, enabled := range m.enabled {
+		if enabled {
+			count++
+		}
+	}
+	return count
+}
+
+// String returns the mask as an ASCII template, see ParseMask
+func (m *Mask) String() string {
+	var result strings.Builder
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.Enabled(x, y) {
+				result.WriteByte('#')
+			} else {
+				result.WriteByte('.')
+			}
+		}
+		result.WriteByte('\n')
+	}
+	return result.String()
+}
+
+// ParseMask reads a mask from an ASCII template with one character per
+// cell: '#' or 'X' for cells in the maze, '.' or ' ' for cells left out.
+// Lines may have different lengths; missing cells are left out
+func ParseMask(s string) (*Mask, error) {
+	lines := strings.Split(strings.Trim(s, "\n"), "\n")
+	width := 0
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, "\r")
+		width = max(width, len(lines[i]))
+	}
+	if width == 0 {
+		return nil, fmt.Errorf("invalid mask: no cells")
+	}
+
+	mask := &Mask{Width: width, Height: len(lines), enabled: make([]bool, width*len(lines))}
+	for y, line := range lines {
+		for x, c := range []byte(line) {
+			switch c {
+			case '#', 'X':
+				mask.Set(x, y, true)
+			case '.', ' ':
+			default:
+				return nil, fmt.Errorf("invalid mask character %q at line %d, column %d", c, y+1, x+1)
+			}
+		}
+	}
+	if mask.Count() == 0 {
+		return nil, fmt.Errorf("invalid mask: no cells")
+	}
+	return mask, nil
+}
+
+// MaskFromImage creates a mask with one cell per pixel: dark pixels are in
+// the maze, light or transparent ones are left out
+func MaskFromImage(img image.Image) *Mask {
+	bounds := img.Bounds()
+	mask := &Mask{Width: bounds.Dx(), Height: bounds.Dy(), enabled: make([]bool, bounds.Dx()*bounds.Dy())}
+	for y := 0; y < mask.Height; y++ {
+		for x := 0; x < mask.Width; x++ {
+			gray := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			_, _, _, alpha := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			mask.Set(x, y, alpha >= 0x8000 && gray.Y < 0x80)
+		}
+	}
+	return mask
+}
+
+// LoadMaskPNG reads a black-and-white PNG image as a mask, see MaskFromImage
+func LoadMaskPNG(r io.Reader) (*Mask, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("decoding mask image: %w", err)
+	}
+	mask := MaskFromImage(img)
+	if mask.Count() == 0 {
+		return nil, fmt.Errorf("invalid mask: no dark pixels")
+	}
+	return mask, nil
+}
+
+// CircleMask returns a mask shaped like the largest ellipse that fits in the
+// grid
+func CircleMask(width, height int) *Mask {
+	mask := &Mask{Width: width, Height: height, enabled: make([]bool, width*height)}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// Cell center, scaled so that the ellipse is the unit circle
+			cx := (float64(x)+0.5)/float64(width)*2 - 1
+			cy := (float64(y)+0.5)/float64(height)*2 - 1
+			mask.Set(x, y, cx*cx+cy*cy <= 1)
+		}
+	}
+	return mask
+}
+
+// HeartMask returns a heart shaped mask filling the grid
+func HeartMask(width, height int) *Mask {
+	mask := &Mask{Width: width, Height: height, enabled: make([]bool, width*height)}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// Map the cell center onto the area of the heart curve
+			// (x² + y² - 1)³ - x²y³ <= 0, which spans roughly [-1.2, 1.2]
+			// horizontally and [-1, 1.25] vertically, pointing down
+			hx := ((float64(x)+0.5)/float64(width)*2 - 1) * 1.2
+			hy := 1.25 - (float64(y)+0.5)/float64(height)*2.25
+			a := hx*hx + hy*hy - 1
+			mask.Set(x, y, a*a*a-hx*hx*math.Pow(hy, 3) <= 0)
+		}
+	}
+	return mask
+}
+
+// largestRegion returns a copy of the mask that keeps only the biggest
+// group of enabled cells connected to each other in the given topology, or
+// nil if no cell is enabled
+func (m *Mask) largestRegion(topology Topology) *Mask {
+	region := make([]int, len(m.enabled))
+	bestRegion, bestSize := 0, 0
+	next := 1
+	for start, enabled := range m.enabled {
+		if !enabled || region[start] != 0 {
+			continue
+		}
+		size := 0
+		region[start] = next
+		queue := []int{start}
+		for len(queue) > 0 {
+			cell := queue[0]
+			queue = queue[1:]
+			size++
+			x, y := cell%m.Width, cell/m.Width
+			for _, dir := range topology.Directions(x, y) {
+				nx, ny := topology.Neighbor(x, y, dir)
+				if n := ny*m.Width + nx; m.Enabled(nx, ny) && region[n] == 0 {
+					region[n] = next
+					queue = append(queue, n)
+				}
+			}
+		}
+		if size > bestSize {
+			bestRegion, bestSize = next, size
+		}
+		next++
+	}
+	if bestSize == 0 {
+		return nil
+	}
+
+	largest := &Mask{Width: m.Width, Height: m.Height, enabled: make([]bool, len(m.enabled))}
+	for i, r := range region {
+		largest.enabled[i] = r == bestRegion
+	}
+	return largest
+}
+
+// maskCells lists the cells a mask leaves in a maze, row by row, along
+// with the mask it was made for
+type maskCells struct {
+	mask  *Mask
+	cells []Position
+}
+
+// enabledCells returns the cells of a masked maze that are not masked out,
+// row by row. The list is made once for each mask the maze is given, as
+// generators like Wilson's pick random cells over and over
+func (m *Maze) enabledCells() []Position {
+	if m.enabled.mask != m.Mask {
+		var cells []Position
+		for y := 0; y < m.Height; y++ {
+			for x := 0; x < m.Width; x++ {
+				if m.IsValidPosition(x, y) {
+					cells = append(cells, Position{X: x, Y: y})
+				}
+			}
+		}
+		m.enabled = maskCells{mask: m.Mask, cells: cells}
+	}
+	return m.enabled.cells
+}
+
+// randomCell picks a random cell of the maze that is not masked out
+func (m *Maze) randomCell(rng *rand.Rand) Position {
+	if m.Mask == nil {
+		return Position{X: rng.Intn(m.Width), Y: rng.Intn(m.Height)}
+	}
+	cells := m.enabledCells()
+	if len(cells) == 0 {
+		return Position{}
+	}
+	return cells[rng.Intn(len(cells))]
+}
+
+// cellCount returns the number of cells that are not masked out
+func (m *Maze) cellCount() int {
+	if m.Mask == nil {
+		return m.Width * m.Height
+	}
+	return len(m.enabledCells())
+}
+
+// firstCell returns the first cell that is not masked out, nor a crossing,
+// row by row
+func (m *Maze) firstCell() Position {
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.IsValidPosition(x, y) && !m.isCrossing(x, y) {
+				return Position{X: x, Y: y}
+			}
+		}
+	}
+	return Position{}
+}
+
+// lastCell returns the last cell that is not masked out, nor a crossing,
+// row by row
+func (m *Maze) lastCell() Position {
+	for y := m.Height - 1; y >= 0; y-- {
+		for x := m.Width - 1; x >= 0; x-- {
+			if m.IsValidPosition(x, y) && !m.isCrossing(x, y) {
+				return Position{X: x, Y: y}
+			}
+		}
+	}
+	return Position{}
+}
+
+// newCellGrid returns a Height x Width grid of flags, where masked out cells
+// start as true so that searches never enter them
+func (m *Maze) newCellGrid() [][]bool {
+	grid := make([][]bool, m.Height)
+	for y := range grid {
+		grid[y] = make([]bool, m.Width)
+		for x := range grid[y] {
+			grid[y][x] = !m.IsValidPosition(x, y)
+		}
+	}
+	return grid
+}
+
+// connect knocks down walls between groups of cells that cannot reach
+// each other, until every cell can reach every other one. Generators that
+// work row by row can leave such groups behind when parts of a row are
+// masked out, or when the cells lack the walls they work with
+func (m *Maze) connect(rng *rand.Rand) {
+	sets := newDisjointSets(m.Width * m.Height)
+	type wall struct {
+		x, y int
+		dir  MazeDirection
+	}
+	var closed []wall
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if !m.IsValidPosition(x, y) {
+				continue
+			}
+			if m.isCrossing(x, y) {
+				// The two passages through a crossing each join their ends
+				ends, _ := m.crossingNeighbors(x, y)
+				sets.union(ends[0].Y*m.Width+ends[0].X, ends[1].Y*m.Width+ends[1].X)
+				sets.union(ends[2].Y*m.Width+ends[2].X, ends[3].Y*m.Width+ends[3].X)
+				continue
+			}
+			// Every wall is seen from both sides, keep the side of the cell
+			// that comes first
+			for _, dir := range m.Directions(x, y) {
+				nx, ny := m.Neighbor(x, y, dir)
+				if !m.IsValidPosition(nx, ny) || m.isCrossing(nx, ny) || ny*m.Width+nx <= y*m.Width+x {
+					continue
+				}
+				if m.HasWall(x, y, dir) {
+					closed = append(closed, wall{x, y, dir})
+				} else {
+					sets.union(y*m.Width+x, ny*m.Width+nx)
+				}
+			}
+		}
+	}
+
+	rng.Shuffle(len(closed), func(i, j int) {
+		closed[i], closed[j] = closed[j], closed[i]
+	})
+	for _, w := range closed {
+		nx, ny := m.Neighbor(w.x, w.y, w.dir)
+		if sets.union(w.y*m.Width+w.x, ny*m.Width+nx) {
+			m.RemoveWall(w.x, w.y, w.dir)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package game
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ringMask = `
+.###.
+##.##
+#...#
+##.##
+.###.
+`
+
+func TestParseMask(t *testing.T) {
+	mask, err := ParseMask(ringMask)
+	require.NoError(t, err)
+	assert.Equal(t, 5, mask.Width)
+	assert.Equal(t, 5, mask.Height)
+	assert.Equal(t, 16, mask.Count())
+	assert.True(t, mask.Enabled(1, 0))
+	assert.False(t, mask.Enabled(0, 0))
+	assert.False(t, mask.Enabled(5, 0), "cells outside the mask are never enabled")
+	assert.Equal(t, ringMask[1:], mask.String())
+
+	mask, err = ParseMask("X\r\nXX X\r\n")
+	require.NoError(t, err)
+	assert.Equal(t, 4, mask.Width, "the longest line sets the width")
+	assert.Equal(t, 4, mask.Count())
+
+	_, err = ParseMask("##\n#o")
+	assert.ErrorContains(t, err, "line 2, column 2")
+	_, err = ParseMask("...")
+	assert.Error(t, err)
+}
+
+func TestLoadMaskPNG(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(0, 0, color.Black)
+	img.Set(1, 0, color.White)
+	img.Set(2, 0, color.NRGBA{0, 0, 0, 0}) // Transparent
+	img.Set(0, 1, color.NRGBA{40, 40, 40, 255})
+	img.Set(1, 1, color.Black)
+	img.Set(2, 1, color.NRGBA{200, 200, 200, 255})
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	mask, err := LoadMaskPNG(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "#..\n##.\n", mask.String())
+
+	_, err = LoadMaskPNG(bytes.NewReader([]byte("not a png")))
+	assert.Error(t, err)
+}
+
+func TestShapeMasks(t *testing.T) {
+	for name, mask := range map[string]*Mask{
+		"circle": CircleMask(10, 10),
+		"heart":  HeartMask(10, 10),
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.False(t, mask.Enabled(0, 0), "corners are cut off")
+			assert.True(t, mask.Enabled(5, 5), "the middle is in")
+			assert.Equal(t, mask.Count(), mask.largestRegion(SquareTopology{}).Count(), "the shape is in one piece")
+		})
+	}
+}
+
+func TestMaskedMaze(t *testing.T) {
+	mask, err := ParseMask(ringMask)
+	require.NoError(t, err)
+	maze := NewMaskedMaze(mask)
+
+	assert.False(t, maze.IsValidPosition(2, 2))
+	assert.True(t, maze.HasWall(2, 2, North), "masked cells count as walls")
+
+	// Carving into the hole is an exit, just as at the edge of the grid
+	maze.RemoveWall(2, 0, South)
+	assert.Equal(t, []Exit{{Position: Position{2, 0}, Direction: South}}, maze.Exits())
+}
+
+func TestGeneratorsWithMask(t *testing.T) {
+	mask, err := ParseMask(`
+..####..
+.######.
+###..###
+##....##
+###..###
+.######.
+..####..
+##....##`)
+	require.NoError(t, err)
+
+	for a := 0; a < mazeAlgorithmCount; a++ {
+		algorithm := MazeAlgorithm(a)
+		t.Run(algorithm.String(), func(t *testing.T) {
+			for seed := int64(0); seed < 5; seed++ {
+				maze := NewMaskedMaze(mask.largestRegion(SquareTopology{}))
+				algorithm.Generator().Generate(maze, rand.New(rand.NewSource(seed)))
+				maze.connect(rand.New(rand.NewSource(seed)))
+				assertPerfectMaze(t, maze)
+
+				// Generate drops the bottom row, which is not connected
+				maze, start := MazeConfig{Mask: mask, Seed: seed, Generator: algorithm.Generator()}.Generate()
+				assert.False(t, maze.IsValidPosition(0, 7))
+				assert.True(t, maze.IsValidPosition(start.X, start.Y))
+				require.NotNil(t, maze.Exit)
+				_, ok := maze.ShortestPath(start)
+				assert.True(t, ok, "the exit should be reachable")
+			}
+		})
+	}
+}
+
+func TestRandomCellMasked(t *testing.T) {
+	mask, err := ParseMask(ringMask)
+	require.NoError(t, err)
+	maze := NewMaskedMaze(mask)
+	assert.Equal(t, mask.Count(), maze.cellCount())
+
+	rng := rand.New(rand.NewSource(1))
+	seen := map[Position]bool{}
+	for i := 0; i < 1000; i++ {
+		p := maze.randomCell(rng)
+		assert.True(t, maze.IsValidPosition(p.X, p.Y), "%v is masked out", p)
+		seen[p] = true
+	}
+	assert.Len(t, seen, mask.Count(), "every cell can be picked")
+
+	// Replacing the mask replaces the cells to pick from
+	maze.Mask = NewMask(1, 1)
+	assert.Equal(t, 1, maze.cellCount())
+	assert.Equal(t, Position{}, maze.randomCell(rng))
+}
+
+func BenchmarkGenerateMasked(b *testing.B) {
+	mask := CircleMask(150, 150)
+	for _, algorithm := range []MazeAlgorithm{AlgorithmWilson, AlgorithmAldousBroder} {
+		b.Run(algorithm.String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				MazeConfig{Mask: mask, Seed: int64(i), Generator: algorithm.Generator()}.Generate()
+			}
+		})
+	}
+}
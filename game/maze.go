@@ -1,7 +1,6 @@
 package game
 
 import (
-	"fmt"
 	"math/rand"
 	"strings"
 )
@@ -14,6 +13,17 @@ const (
 	East
 	South
 	West
+	NorthEast
+	SouthEast
+	SouthWest
+	NorthWest
+	// Up and Down lead to the floors above and below in a Tower, through
+	// the stairs of a cell. They are not part of any topology
+	Up
+	Down
+
+	// maxDirections is the number of directions a cell can have walls in
+	maxDirections = int(Down) + 1
 )
 
 // String returns the string representation of a direction
@@ -27,6 +37,18 @@ func (d MazeDirection) String() string {
 		return "South"
 	case West:
 		return "West"
+	case NorthEast:
+		return "NorthEast"
+	case SouthEast:
+		return "SouthEast"
+	case SouthWest:
+		return "SouthWest"
+	case NorthWest:
+		return "NorthWest"
+	case Up:
+		return "Up"
+	case Down:
+		return "Down"
 	default:
 		return "Unknown"
 	}
@@ -43,50 +65,201 @@ func (d MazeDirection) Opposite() MazeDirection {
 		return West
 	case West:
 		return East
+	case NorthEast:
+		return SouthWest
+	case SouthWest:
+		return NorthEast
+	case SouthEast:
+		return NorthWest
+	case NorthWest:
+		return SouthEast
+	case Up:
+		return Down
+	case Down:
+		return Up
 	default:
 		return d
 	}
 }
 
-// Cell represents a single cell in the maze
-type Cell struct {
-	// Walls indicates whether there are walls in each direction
-	// The index represents: 0=North, 1=East, 2=South, 3=West
-	Walls [4]bool
+var directionOffsets = [...]struct{ dx, dy int }{
+	North: {0, -1},
+	East:  {1, 0},
+	South: {0, 1},
+	West:  {-1, 0},
 }
 
+// Offset returns how x and y change when moving one cell in the direction
+// on a square grid. Other topologies have their own rules, see
+// Topology.Neighbor
+func (d MazeDirection) Offset() (dx, dy int) {
+	if d < 0 || int(d) >= len(directionOffsets) {
+		return 0, 0
+	}
+	offset := directionOffsets[d]
+	return offset.dx, offset.dy
+}
+
+// wallSlots gives each direction its place among the walls a cell holds.
+// A wall is shared by the cells on both sides of it, so only one of each
+// pair of opposite directions has a slot, and the wall in the other
+// direction is held by the neighbor on the other side. Up and Down lead to
+// other floors and are not shared
+var wallSlots = [maxDirections]int{
+	North:     0,
+	East:      1,
+	NorthEast: 2,
+	SouthEast: 3,
+	Up:        4,
+	Down:      5,
+	South:     -1,
+	West:      -1,
+	SouthWest: -1,
+	NorthWest: -1,
+}
+
+// wallsPerCell is the number of walls each cell holds
+const wallsPerCell = 6
+
 // Maze represents a 2D grid maze
 type Maze struct {
 	Width  int
 	Height int
-	Grid   [][]Cell
+	// Topology decides the shape of the cells and how they connect; nil
+	// means a grid of squares
+	Topology Topology
+	// Start is where the player begins
+	Start Position
+	// Exit is the opening the player escapes through, or nil if the maze
+	// has not been given one
+	Exit *Exit
+	// Goal, when set, is a cell that wins the game as soon as the player
+	// reaches it. Mazes without an outer wall, like tori, use it instead
+	// of an exit
+	Goal *Position
+	// Mask, when set, leaves some cells out of the maze so it can take any
+	// shape. Masked out cells behave as if they were outside the grid. The
+	// mask must not be changed once the maze is in use, only replaced
+	Mask *Mask
+	// Seed and Generator record how a generated maze was made: the seed of
+	// its MazeConfig and the name of the algorithm that carved it
+	Seed      int64
+	Generator string
+
+	// walls holds one bit per wall, set where there is a wall. The grid is
+	// surrounded by a margin of one cell on every side, so walls on the
+	// edge are held whichever side owns them
+	walls bitset
+	// crossings holds two bits per cell with its Crossing, and is only
+	// allocated once the maze has a crossing
+	crossings bitset
+	// enabled caches the cells Mask leaves in the maze, see enabledCells
+	enabled maskCells
 }
 
 // NewMaze creates a new maze with the specified dimensions
 // Initially, all cells have walls on all sides
 func NewMaze(width, height int) *Maze {
-	maze := &Maze{
+	return &Maze{
 		Width:  width,
 		Height: height,
-		Grid:   make([][]Cell, height),
+		walls:  newBitset((width+2)*(height+2)*wallsPerCell, true),
 	}
+}
 
-	// Initialize all cells with walls
-	for y := 0; y < height; y++ {
-		maze.Grid[y] = make([]Cell, width)
-		for x := 0; x < width; x++ {
-			maze.Grid[y][x] = Cell{
-				Walls: [4]bool{true, true, true, true},
-			}
-		}
-	}
+// NewMazeWithTopology creates a new maze of the given topology, with walls
+// on all sides of every cell
+func NewMazeWithTopology(width, height int, topology Topology) *Maze {
+	maze := NewMaze(width, height)
+	maze.Topology = topology
+	return maze
+}
 
+// NewMaskedMaze creates a new maze shaped by the mask, with walls on all
+// sides of every cell
+func NewMaskedMaze(mask *Mask) *Maze {
+	maze := NewMaze(mask.Width, mask.Height)
+	maze.Mask = mask
 	return maze
 }
 
 // IsValidPosition checks if the given coordinates are within the maze bounds
+// and not masked out
 func (m *Maze) IsValidPosition(x, y int) bool {
-	return x >= 0 && x < m.Width && y >= 0 && y < m.Height
+	return x >= 0 && x < m.Width && y >= 0 && y < m.Height &&
+		(m.Mask == nil || m.Mask.Enabled(x, y))
+}
+
+// Geometry returns the maze's topology, defaulting to square cells when
+// the Topology field is nil
+func (m *Maze) Geometry() Topology {
+	if m.Topology == nil {
+		return SquareTopology{}
+	}
+	return m.Topology
+}
+
+// isSquare reports whether the maze is a plain grid of squares
+func (m *Maze) isSquare() bool {
+	_, square := m.Geometry().(SquareTopology)
+	return square
+}
+
+// Directions returns the directions in which the cell at the given
+// position has walls, clockwise. The slice must not be modified
+func (m *Maze) Directions(x, y int) []MazeDirection {
+	return m.Geometry().Directions(x, y)
+}
+
+// Neighbor returns the position of the cell on the other side of the wall
+// in the given direction, which may be outside the maze
+func (m *Maze) Neighbor(x, y int, direction MazeDirection) (int, int) {
+	return m.Geometry().Neighbor(x, y, direction)
+}
+
+// IsGoal reports whether the position is the maze's goal cell
+func (m *Maze) IsGoal(p Position) bool {
+	return m.Goal != nil && *m.Goal == p
+}
+
+// hasDirection reports whether the cell at the given position has a wall
+// in the given direction at all
+func (m *Maze) hasDirection(x, y int, direction MazeDirection) bool {
+	for _, d := range m.Directions(x, y) {
+		if d == direction {
+			return true
+		}
+	}
+	return false
+}
+
+// directionCount returns the most walls any cell of the maze has
+func (m *Maze) directionCount() int {
+	count := 0
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			count = max(count, len(m.Directions(x, y)))
+		}
+	}
+	return count
+}
+
+// wallIndex returns the bit in m.walls of the wall in the given direction
+// of the cell at the given position. Walls without a slot of their own are
+// held by the neighbor, which may be in the margin around the grid
+func (m *Maze) wallIndex(x, y int, direction MazeDirection) int {
+	slot := wallSlots[direction]
+	if slot < 0 {
+		if m.Topology == nil {
+			// Square grids are the common case, skip the interface call
+			dx, dy := direction.Offset()
+			x, y = x+dx, y+dy
+		} else {
+			x, y = m.Topology.Neighbor(x, y, direction)
+		}
+		slot = wallSlots[direction.Opposite()]
+	}
+	return ((y+1)*(m.Width+2)+x+1)*wallsPerCell + slot
 }
 
 // HasWall checks if there's a wall in the specified direction at the given position
@@ -94,36 +267,17 @@ func (m *Maze) HasWall(x, y int, direction MazeDirection) bool {
 	if !m.IsValidPosition(x, y) {
 		return true // Out of bounds is considered a wall
 	}
-	return m.Grid[y][x].Walls[direction]
+	return m.walls.get(m.wallIndex(x, y, direction))
 }
 
 // RemoveWall removes a wall in the specified direction at the given position
-// This also removes the corresponding wall from the adjacent cell
+// The wall is shared, so the adjacent cell loses it too. Up and Down lead
+// to another floor, which is up to the Tower to change
 func (m *Maze) RemoveWall(x, y int, direction MazeDirection) {
 	if !m.IsValidPosition(x, y) {
 		return
 	}
-
-	// Remove wall from current cell
-	m.Grid[y][x].Walls[direction] = false
-
-	// Remove wall from adjacent cell
-	var adjX, adjY int
-
-	switch direction {
-	case North:
-		adjX, adjY = x, y-1
-	case East:
-		adjX, adjY = x+1, y
-	case South:
-		adjX, adjY = x, y+1
-	case West:
-		adjX, adjY = x-1, y
-	}
-
-	if m.IsValidPosition(adjX, adjY) {
-		m.Grid[adjY][adjX].Walls[direction.Opposite()] = false
-	}
+	m.walls.set(m.wallIndex(x, y, direction), false)
 }
 
 // AddWall adds a wall in the specified direction at the given position
@@ -131,46 +285,52 @@ func (m *Maze) AddWall(x, y int, direction MazeDirection) {
 	if !m.IsValidPosition(x, y) {
 		return
 	}
-
-	// Add wall to current cell
-	m.Grid[y][x].Walls[direction] = true
-
-	// Add wall to adjacent cell
-	var adjX, adjY int
-
-	switch direction {
-	case North:
-		adjX, adjY = x, y-1
-	case East:
-		adjX, adjY = x+1, y
-	case South:
-		adjX, adjY = x, y+1
-	case West:
-		adjX, adjY = x-1, y
-	}
-
-	if m.IsValidPosition(adjX, adjY) {
-		m.Grid[adjY][adjX].Walls[direction.Opposite()] = true
-	}
+	m.walls.set(m.wallIndex(x, y, direction), true)
 }
 
 // String returns an ASCII representation of the maze
+// The format only describes square grids
 func (m *Maze) String() string {
+	return m.text(false)
+}
+
+// MarkedString is like String, but also marks the start, the exit and the
+// special cells of the maze, see cellMarkers
+func (m *Maze) MarkedString() string {
+	return m.text(true)
+}
+
+// text returns the ASCII representation of the maze, with or without
+// markers in the cells. The plain format always closes the top and left
+// borders, the marked one shows the openings there too
+func (m *Maze) text(markers bool) string {
 	var result strings.Builder
 
 	// Write top border
 	for x := 0; x < m.Width; x++ {
-		result.WriteString("+--")
+		if markers && !m.HasWall(x, 0, North) {
+			result.WriteString("+  ")
+		} else {
+			result.WriteString("+--")
+		}
 	}
 	result.WriteString("+\n")
 
 	// For each row
 	for y := 0; y < m.Height; y++ {
 		// First line: vertical walls and spaces
-		result.WriteString("|")
+		if markers && !m.HasWall(0, y, West) {
+			result.WriteString(" ")
+		} else {
+			result.WriteString("|")
+		}
 		for x := 0; x < m.Width; x++ {
-			// Write two spaces for the cell
-			result.WriteString("  ")
+			// Write two spaces for the cell, or its markers
+			if markers {
+				result.WriteString(m.cellMarkers(x, y))
+			} else {
+				result.WriteString("  ")
+			}
 			// Write east wall (if present)
 			if m.HasWall(x, y, East) {
 				result.WriteString("|")
@@ -196,165 +356,145 @@ func (m *Maze) String() string {
 	return result.String()
 }
 
-// ParseMaze converts a string representation back into a Maze struct
-func ParseMaze(s string) (*Maze, error) {
-	lines := strings.Split(strings.TrimSpace(s), "\n")
-	if len(lines) < 3 { // Need at least top border + one cell row + bottom border
-		return nil, fmt.Errorf("invalid maze string: too few lines")
-	}
-
-	// Calculate dimensions
-	width := (len(lines[0]) - 1) / 3 // Each cell is 3 chars wide including walls
-	height := (len(lines) - 1) / 2   // Each cell is 2 lines tall including walls
-
-	if width < 1 || height < 1 {
-		return nil, fmt.Errorf("invalid maze dimensions: width=%d, height=%d", width, height)
-	}
-
-	maze := NewMaze(width, height)
-
-	// Process each cell
-	for y := 0; y < height; y++ {
-		// Check vertical walls (in the cell content line)
-		cellLine := lines[y*2+1]
-		if len(cellLine) != width*3+1 {
-			return nil, fmt.Errorf("invalid line length at y=%d", y)
-		}
-
-		for x := 0; x < width; x++ {
-			// Check west wall
-			if x == 0 {
-				if cellLine[0] != '|' {
-					maze.RemoveWall(x, y, West)
-				}
-			}
-
-			// Check east wall
-			if cellLine[x*3+3] == ' ' {
-				maze.RemoveWall(x, y, East)
-			}
-		}
+// Position represents a 2D position in the maze
+type Position struct {
+	X, Y int
+}
 
-		// Check horizontal walls (in the wall line)
-		if y < height {
-			wallLine := lines[y*2+2]
-			if len(wallLine) != width*3+1 {
-				return nil, fmt.Errorf("invalid wall line length at y=%d", y)
-			}
+// NewSeed returns a fresh seed for GenerateMaze, for when the caller has no
+// particular maze in mind
+func NewSeed() int64 {
+	return rand.Int63()
+}
 
-			for x := 0; x < width; x++ {
-				// Check south wall
-				if wallLine[x*3+1:x*3+3] == "  " {
-					maze.RemoveWall(x, y, South)
-				}
-			}
-		}
+// MazeConfig describes how to generate a maze
+// The same config always produces the same maze and start position
+type MazeConfig struct {
+	Width  int
+	Height int
+	Seed   int64
+	// Generator carves the passages; a nil Generator uses DFSGenerator
+	Generator Generator
+	// Braid is the fraction of dead ends, between 0 and 1, to turn into
+	// loops once the passages are carved
+	Braid float64
+	// Difficulty, when set, makes Generate retry until the maze's difficulty
+	// score falls inside the band, keeping the closest maze if none does
+	Difficulty DifficultyBand
+	// Placement decides where the start and the exit go, and MinDistance is
+	// the shortest allowed route between them for PlacementMinDistance
+	Placement   Placement
+	MinDistance int
+	// Start and Exit, when set, override the placement; the other one is
+	// still placed according to the strategy. Mazes without an outer wall
+	// get a goal cell instead of an exit, and ignore Exit. A Start that is
+	// not a cell of the maze, or is a crossing, and an Exit that is not a
+	// wall on the outside of the maze are ignored too
+	Start *Position
+	Exit  *Exit
+	// Mask, when set, gives the maze its shape and replaces Width and
+	// Height. Cells that are not connected to the largest group of enabled
+	// cells are left out
+	Mask *Mask
+	// Grid selects the shape of the cells
+	Grid Grid
+	// Floors is how many floors GenerateTower stacks up; Generate always
+	// makes a single one
+	Floors int
+}
 
-		// Check north walls for first row
-		if y == 0 {
-			topLine := lines[0]
-			for x := 0; x < width; x++ {
-				if topLine[x*3+1:x*3+3] == "  " {
-					maze.RemoveWall(x, y, North)
-				}
-			}
-		}
-	}
+// maxDifficultyAttempts bounds how many mazes Generate tries when looking
+// for one inside the difficulty band
+const maxDifficultyAttempts = 100
 
-	return maze, nil
+// Generate creates the maze described by the config
+// It returns the maze and the starting position for the player
+func (c MazeConfig) Generate() (*Maze, Position) {
+	return c.generate(rand.New(rand.NewSource(c.Seed)))
 }
 
-// Position represents a 2D position in the maze
-type Position struct {
-	X, Y int
+func (c MazeConfig) generator() Generator {
+	if c.Generator == nil {
+		return DFSGenerator{}
+	}
+	return c.Generator
 }
 
 // GenerateMaze creates a new random maze with the specified dimensions
 // It returns the maze and the starting position for the player
-func GenerateMaze(width, height int) (*Maze, Position) {
-	maze := NewMaze(width, height)
+// The same seed and dimensions always produce the same maze and start position
+func GenerateMaze(width, height int, seed int64) (*Maze, Position) {
+	return MazeConfig{Width: width, Height: height, Seed: seed}.Generate()
+}
 
-	// Create a visited cells tracker
-	visited := make([][]bool, height)
-	for i := range visited {
-		visited[i] = make([]bool, width)
-	}
+// GenerateMazeFromRand is like GenerateMaze, but draws all of its randomness
+// from the given source
+func GenerateMazeFromRand(width, height int, rng *rand.Rand) (*Maze, Position) {
+	return MazeConfig{Width: width, Height: height}.generate(rng)
+}
 
-	// Start from a random position
-	startX := rand.Intn(width)
-	startY := rand.Intn(height)
-
-	// Generate the maze using DFS
-	generateMazeDFS(maze, visited, startX, startY)
-
-	// Create an exit by removing a random external wall
-	// First, decide which wall to remove (North, South, East, or West edge)
-	edge := rand.Intn(4)
-	var x, y int
-	var direction MazeDirection
-
-	switch edge {
-	case 0: // North edge
-		x = rand.Intn(width)
-		y = 0
-		direction = North
-	case 1: // South edge
-		x = rand.Intn(width)
-		y = height - 1
-		direction = South
-	case 2: // East edge
-		x = width - 1
-		y = rand.Intn(height)
-		direction = East
-	case 3: // West edge
-		x = 0
-		y = rand.Intn(height)
-		direction = West
+func (c MazeConfig) generate(rng *rand.Rand) (*Maze, Position) {
+	maze, start := c.generateOnce(rng)
+	if c.Difficulty.IsZero() {
+		return maze, start
 	}
 
-	// Remove the selected wall
-	maze.RemoveWall(x, y, direction)
-
-	return maze, Position{X: startX, Y: startY}
-}
-
-// generateMazeDFS is a recursive function that implements the depth-first search algorithm
-func generateMazeDFS(maze *Maze, visited [][]bool, x, y int) {
-	visited[y][x] = true
-
-	// Define possible directions in a random order
-	directions := []MazeDirection{North, East, South, West}
-	shuffleDirections(directions)
-
-	// Try each direction
-	for _, dir := range directions {
-		// Calculate new position
-		newX, newY := x, y
-		switch dir {
-		case North:
-			newY--
-		case South:
-			newY++
-		case East:
-			newX++
-		case West:
-			newX--
+	bestDistance := c.Difficulty.distance(maze.Metrics(start).Score())
+	for attempt := 1; attempt < maxDifficultyAttempts && bestDistance > 0; attempt++ {
+		candidate, candidateStart := c.generateOnce(rng)
+		if distance := c.Difficulty.distance(candidate.Metrics(candidateStart).Score()); distance < bestDistance {
+			maze, start, bestDistance = candidate, candidateStart, distance
 		}
+	}
+	return maze, start
+}
 
-		// Check if the new position is valid and unvisited
-		if maze.IsValidPosition(newX, newY) && !visited[newY][newX] {
-			// Remove walls between current and new position
-			maze.RemoveWall(x, y, dir)
-			// Continue with DFS from the new position
-			generateMazeDFS(maze, visited, newX, newY)
+func (c MazeConfig) generateOnce(rng *rand.Rand) (*Maze, Position) {
+	maze := NewMazeWithTopology(c.Width, c.Height, c.Grid.Topology(c.Width, c.Height))
+	if c.Mask != nil {
+		topology := c.Grid.Topology(c.Mask.Width, c.Mask.Height)
+		if mask := c.Mask.largestRegion(topology); mask != nil {
+			maze = NewMaskedMaze(mask)
+			maze.Topology = topology
 		}
 	}
-}
 
-// shuffleDirections randomly shuffles a slice of directions
-func shuffleDirections(dirs []MazeDirection) {
-	for i := len(dirs) - 1; i > 0; i-- {
-		j := rand.Intn(i + 1)
-		dirs[i], dirs[j] = dirs[j], dirs[i]
+	maze.Seed = c.Seed
+	maze.Generator = c.generator().Name()
+
+	// Start from a random position, unless the placement says otherwise
+	randomStart := maze.randomCell(rng)
+
+	// Carve the passages, out of the start where the generator allows
+	if rooted, ok := c.generator().(rootedGenerator); ok {
+		rooted.generateFrom(maze, randomStart, rng)
+	} else {
+		c.generator().Generate(maze, rng)
 	}
+	if maze.Mask != nil || !maze.isSquare() {
+		maze.connect(rng)
+	}
+	// Nobody can stand on a crossing
+	for maze.isCrossing(randomStart.X, randomStart.Y) {
+		randomStart = maze.randomCell(rng)
+	}
+	if c.Braid > 0 {
+		maze.Braid(c.Braid, rng)
+	}
+
+	// Without an outer wall there is nowhere to exit, aim for a goal cell
+	if len(boundaryWalls(maze)) == 0 {
+		start, goal := c.placeGoal(maze, randomStart, rng)
+		maze.Start = start
+		maze.Goal = &goal
+		return maze, start
+	}
+
+	// Create the exit by removing an external wall
+	start, exit := c.place(maze, randomStart, rng)
+	maze.RemoveWall(exit.Position.X, exit.Position.Y, exit.Direction)
+	maze.Start = start
+	maze.Exit = &exit
+
+	return maze, start
 }
@@ -1,6 +1,8 @@
 package game
 
 import (
+	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -194,3 +196,207 @@ func TestMazeRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateMazeSeed(t *testing.T) {
+	t.Run("same seed gives same maze", func(t *testing.T) {
+		maze1, start1 := GenerateMaze(10, 8, 42)
+		maze2, start2 := GenerateMaze(10, 8, 42)
+		assert.Equal(t, maze1.String(), maze2.String(), "mazes generated from the same seed should match")
+		assert.Equal(t, start1, start2, "start positions generated from the same seed should match")
+	})
+
+	t.Run("seeds keep their mazes", func(t *testing.T) {
+		// Generated before generators could be chosen, and must not change
+		// so that seeds shared by players still work
+		const want = `+--+--+--+--+--+
+|     |         
++  +--+  +--+  +
+|  |     |     |
++  +--+--+  +--+
+|     |     |  |
++  +  +  +--+  +
+|  |  |     |  |
++  +--+--+  +  +
+|              |
++--+--+--+--+--+
+`
+		maze, start := GenerateMaze(5, 5, 2)
+		assert.Equal(t, want, maze.String())
+		assert.Equal(t, Position{1, 1}, start)
+	})
+
+	t.Run("different seeds give different mazes", func(t *testing.T) {
+		maze1, _ := GenerateMaze(10, 8, 1)
+		maze2, _ := GenerateMaze(10, 8, 2)
+		assert.NotEqual(t, maze1.String(), maze2.String(), "mazes generated from different seeds should differ")
+	})
+}
+
+// cellMaze is the original layout of Maze, a grid of cells each holding a
+// flag for every one of its walls, so that shared walls are stored twice.
+// It is kept as a reference for the packed layout
+type cellMaze struct {
+	width, height int
+	topology      Topology
+	grid          [][]cell
+}
+
+type cell struct {
+	walls    [maxDirections]bool
+	crossing Crossing
+}
+
+func newCellMaze(width, height int, topology Topology) *cellMaze {
+	maze := &cellMaze{width: width, height: height, topology: topology, grid: make([][]cell, height)}
+	var closed cell
+	for i := range closed.walls {
+		closed.walls[i] = true
+	}
+	for y := range maze.grid {
+		maze.grid[y] = make([]cell, width)
+		for x := range maze.grid[y] {
+			maze.grid[y][x] = closed
+		}
+	}
+	return maze
+}
+
+func (m *cellMaze) isValidPosition(x, y int) bool {
+	return x >= 0 && x < m.width && y >= 0 && y < m.height
+}
+
+func (m *cellMaze) hasWall(x, y int, direction MazeDirection) bool {
+	if !m.isValidPosition(x, y) {
+		return true
+	}
+	return m.grid[y][x].walls[direction]
+}
+
+func (m *cellMaze) setWall(x, y int, direction MazeDirection, wall bool) {
+	if !m.isValidPosition(x, y) {
+		return
+	}
+	m.grid[y][x].walls[direction] = wall
+	if direction == Up || direction == Down {
+		return
+	}
+	if nx, ny := m.topology.Neighbor(x, y, direction); m.isValidPosition(nx, ny) {
+		m.grid[ny][nx].walls[direction.Opposite()] = wall
+	}
+}
+
+func TestPackedWallsMatchCells(t *testing.T) {
+	const width, height = 7, 6
+	for g := 0; g < gridCount; g++ {
+		grid := Grid(g)
+		t.Run(grid.String(), func(t *testing.T) {
+			topology := grid.Topology(width, height)
+			packed := NewMazeWithTopology(width, height, topology)
+			cells := newCellMaze(width, height, topology)
+
+			rng := rand.New(rand.NewSource(int64(g)))
+			for i := 0; i < 500; i++ {
+				// Include positions just outside the grid, which are no-ops
+				x, y := rng.Intn(width+2)-1, rng.Intn(height+2)-1
+				directions := append([]MazeDirection{Up, Down}, topology.Directions(max(0, x), max(0, y))...)
+				dir := directions[rng.Intn(len(directions))]
+				wall := rng.Intn(3) == 0
+				cells.setWall(x, y, dir, wall)
+				if wall {
+					packed.AddWall(x, y, dir)
+				} else {
+					packed.RemoveWall(x, y, dir)
+				}
+
+				for y := -1; y <= height; y++ {
+					for x := -1; x <= width; x++ {
+						for _, dir := range append([]MazeDirection{Up, Down}, topology.Directions(max(0, x), max(0, y))...) {
+							if cells.hasWall(x, y, dir) != packed.HasWall(x, y, dir) {
+								t.Fatalf("step %d: wall at (%d, %d) %s differs", i, x, y, dir)
+							}
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCrossings(t *testing.T) {
+	maze := NewMaze(3, 2)
+	assert.Nil(t, maze.crossings, "no storage until the first crossing")
+	maze.SetCrossing(1, 0, CrossingEastWest)
+	maze.SetCrossing(2, 1, CrossingNorthSouth)
+	maze.SetCrossing(5, 5, CrossingNorthSouth)
+	for y := 0; y < maze.Height; y++ {
+		for x := 0; x < maze.Width; x++ {
+			want := NoCrossing
+			switch (Position{x, y}) {
+			case Position{1, 0}:
+				want = CrossingEastWest
+			case Position{2, 1}:
+				want = CrossingNorthSouth
+			}
+			assert.Equal(t, want, maze.Crossing(x, y), "(%d, %d)", x, y)
+		}
+	}
+	maze.SetCrossing(1, 0, NoCrossing)
+	assert.Equal(t, NoCrossing, maze.Crossing(1, 0))
+}
+
+// BenchmarkMazeLayout compares the packed layout of Maze with the original
+// grid of cells. B/op of the new benchmarks is the memory each layout needs
+func BenchmarkMazeLayout(b *testing.B) {
+	for _, size := range []int{100, 1000} {
+		b.Run(fmt.Sprintf("cells/new/%dx%d", size, size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				newCellMaze(size, size, SquareTopology{})
+			}
+		})
+		b.Run(fmt.Sprintf("packed/new/%dx%d", size, size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				NewMaze(size, size)
+			}
+		})
+
+		// Carve every other wall, then read every wall back
+		b.Run(fmt.Sprintf("cells/walls/%dx%d", size, size), func(b *testing.B) {
+			maze := newCellMaze(size, size, SquareTopology{})
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for y := 0; y < size; y++ {
+					for x := 0; x < size; x++ {
+						maze.setWall(x, y, MazeDirection((x+y)%4), false)
+					}
+				}
+				for y := 0; y < size; y++ {
+					for x := 0; x < size; x++ {
+						for _, dir := range squareDirections {
+							maze.hasWall(x, y, dir)
+						}
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("packed/walls/%dx%d", size, size), func(b *testing.B) {
+			maze := NewMaze(size, size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for y := 0; y < size; y++ {
+					for x := 0; x < size; x++ {
+						maze.RemoveWall(x, y, MazeDirection((x+y)%4))
+					}
+				}
+				for y := 0; y < size; y++ {
+					for x := 0; x < size; x++ {
+						for _, dir := range squareDirections {
+							maze.HasWall(x, y, dir)
+						}
+					}
+				}
+			}
+		})
+	}
+}
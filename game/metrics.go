@@ -0,0 +1,100 @@
+package game
+
+import "math"
+
+// MazeMetrics describes how hard a maze is to solve from a start position
+type MazeMetrics struct {
+	// Cells is the number of cells in the maze
+	Cells int
+	// DeadEnds is the number of cells with a single way in or out
+	DeadEnds int
+	// SolutionLength is the number of moves on the shortest way out, or 0
+	// if there is none
+	SolutionLength int
+	// SolutionTurns is how many times the shortest way out changes direction
+	SolutionTurns int
+	// BranchingFactor is the average number of ways forward from each cell
+	// on the shortest way out, not counting the way back. A plain corridor
+	// has a branching factor of 1
+	BranchingFactor float64
+	// OffSolutionShare is the fraction of cells that are not on the
+	// shortest way out
+	OffSolutionShare float64
+}
+
+// Metrics measures the maze as seen from the given start position
+func (m *Maze) Metrics(start Position) MazeMetrics {
+	metrics := MazeMetrics{
+		Cells:            m.cellCount(),
+		DeadEnds:         len(m.DeadEnds()),
+		OffSolutionShare: 1,
+	}
+
+	path, ok := m.ShortestPath(start)
+	if !ok {
+		return metrics
+	}
+
+	metrics.SolutionLength = path.Len()
+	choices := 0
+	for i, cell := range path.Positions {
+		if i > 0 && path.Directions[i] != path.Directions[i-1] {
+			metrics.SolutionTurns++
+		}
+		choices += m.openSides(cell.X, cell.Y)
+		if i > 0 {
+			choices-- // The way back
+		}
+	}
+	metrics.BranchingFactor = float64(choices) / float64(len(path.Positions))
+	metrics.OffSolutionShare = 1 - float64(len(path.Positions))/float64(metrics.Cells)
+
+	return metrics
+}
+
+// Score combines the metrics into a single difficulty between 0 (trivial)
+// and 1 (very hard). It rewards solutions that are long for the size of
+// the maze, that turn often, since every turn means waiting for the
+// player's direction to come round, and that pass many side branches
+func (mm MazeMetrics) Score() float64 {
+	if mm.SolutionLength == 0 || mm.Cells == 0 {
+		return 0
+	}
+
+	// The solution length compared to the side of a square maze of the
+	// same size; typical perfect mazes score somewhere around 1 to 4
+	length := float64(mm.SolutionLength) / math.Sqrt(float64(mm.Cells))
+	lengthScore := math.Min(1, length/5)
+
+	turnScore := float64(mm.SolutionTurns) / float64(mm.SolutionLength)
+	branchScore := math.Min(1, mm.BranchingFactor-1)
+
+	return 0.5*lengthScore + 0.3*turnScore + 0.2*branchScore
+}
+
+// DifficultyBand is a range of acceptable difficulty scores
+type DifficultyBand struct {
+	Min, Max float64
+}
+
+// IsZero reports whether the band is unset, in which case any maze fits
+func (b DifficultyBand) IsZero() bool {
+	return b.Min == 0 && b.Max == 0
+}
+
+// Contains reports whether the score falls inside the band
+func (b DifficultyBand) Contains(score float64) bool {
+	return b.IsZero() || (score >= b.Min && score <= b.Max)
+}
+
+// distance returns how far the score falls outside the band
+func (b DifficultyBand) distance(score float64) float64 {
+	switch {
+	case b.Contains(score):
+		return 0
+	case score < b.Min:
+		return b.Min - score
+	default:
+		return score - b.Max
+	}
+}
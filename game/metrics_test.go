@@ -0,0 +1,56 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	maze, err := ParseMaze(solverMaze)
+	require.NoError(t, err)
+
+	metrics := maze.Metrics(Position{0, 1})
+	assert.Equal(t, 6, metrics.Cells)
+	assert.Equal(t, 1, metrics.DeadEnds)
+	assert.Equal(t, 5, metrics.SolutionLength)
+	assert.Equal(t, 3, metrics.SolutionTurns)
+	assert.InDelta(t, 1.0, metrics.BranchingFactor, 1e-9)
+	assert.InDelta(t, 1.0/6, metrics.OffSolutionShare, 1e-9)
+	assert.Greater(t, metrics.Score(), 0.0)
+
+	unsolvable := NewMaze(3, 3).Metrics(Position{1, 1})
+	assert.Equal(t, 0, unsolvable.SolutionLength)
+	assert.Equal(t, 0.0, unsolvable.Score())
+}
+
+func TestDifficultyBand(t *testing.T) {
+	band := DifficultyBand{Min: 0.3, Max: 0.6}
+	assert.True(t, band.Contains(0.3))
+	assert.True(t, band.Contains(0.6))
+	assert.False(t, band.Contains(0.2))
+	assert.InDelta(t, 0.1, band.distance(0.2), 1e-9)
+	assert.InDelta(t, 0.1, band.distance(0.7), 1e-9)
+	assert.True(t, DifficultyBand{}.Contains(0.99), "an unset band accepts anything")
+}
+
+func TestGenerateWithDifficulty(t *testing.T) {
+	bands := []DifficultyBand{
+		{Min: 0, Max: 0.35},
+		{Min: 0.35, Max: 0.5},
+		{Min: 0.5, Max: 1},
+	}
+	for _, band := range bands {
+		for seed := int64(0); seed < 10; seed++ {
+			config := MazeConfig{Width: 10, Height: 10, Seed: seed, Difficulty: band}
+			maze, start := config.Generate()
+			score := maze.Metrics(start).Score()
+			assert.True(t, band.Contains(score), "score %.2f should be in %v", score, band)
+
+			again, againStart := config.Generate()
+			assert.Equal(t, maze.String(), again.String(), "retries should be deterministic too")
+			assert.Equal(t, start, againStart)
+		}
+	}
+}
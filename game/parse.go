@@ -0,0 +1,208 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ParseOptions configures how ParseMaze reads a maze. The zero value reads
+// mazes the way ParseMaze always has
+type ParseOptions struct {
+	// Strict checks every corner and wall character, instead of reading
+	// anything that is not blank as a wall, and rejects extra lines after
+	// the bottom border
+	Strict bool
+	// Lenient accepts CRLF line endings, and lines with trailing whitespace
+	// added or stripped, as editors tend to leave them. Lines shorter than
+	// the top border are padded with blanks, so they read as openings
+	Lenient bool
+}
+
+// ParseError is the error ParseMaze returns for malformed input, pointing
+// at the offending character
+type ParseError struct {
+	// Line and Column locate the character in the input, counting from 1
+	Line, Column int
+	// Snippet is the line the character is on
+	Snippet string
+	Msg     string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s: %q", e.Line, e.Column, e.Msg, e.Snippet)
+}
+
+// mazeText is the text of a maze split into lines, remembering where they
+// were in the input so that errors can point there
+type mazeText struct {
+	lines []string
+	// firstLine and firstColumn locate the first character of lines[0] in
+	// the input, counting from 0
+	firstLine, firstColumn int
+}
+
+// splitMazeText splits the input into lines, without the blank space
+// around the maze
+func splitMazeText(s string, lenient bool) mazeText {
+	if lenient {
+		s = strings.ReplaceAll(s, "\r\n", "\n")
+	}
+	leading := s[:len(s)-len(strings.TrimLeftFunc(s, unicode.IsSpace))]
+	return mazeText{
+		lines:       strings.Split(strings.TrimSpace(s), "\n"),
+		firstLine:   strings.Count(leading, "\n"),
+		firstColumn: len(leading) - strings.LastIndex(leading, "\n") - 1,
+	}
+}
+
+// errorf returns a ParseError for the character at the given line and
+// column of the text, counting from 0. A line past the end of the text
+// points just after it
+func (t mazeText) errorf(line, column int, format string, args ...any) error {
+	err := &ParseError{Line: t.firstLine + line + 1, Column: column + 1, Msg: fmt.Sprintf(format, args...)}
+	if line == 0 {
+		err.Column += t.firstColumn
+	}
+	if line < len(t.lines) {
+		err.Snippet = t.lines[line]
+	}
+	return err
+}
+
+// ParseMaze converts a string representation back into a Maze struct
+// It reads both the plain format of String and the marked one of
+// MarkedString
+func ParseMaze(s string) (*Maze, error) {
+	return ParseOptions{}.Parse(s)
+}
+
+// Parse converts a string representation back into a Maze struct, as
+// ParseMaze does, with the given options. Malformed input returns a
+// *ParseError
+func (o ParseOptions) Parse(s string) (*Maze, error) {
+	text := splitMazeText(s, o.Lenient)
+	lines := text.lines
+	if o.Lenient {
+		lines[0] = strings.TrimRightFunc(lines[0], unicode.IsSpace)
+	}
+	if len(lines) < 3 { // Need at least top border + one cell row + bottom border
+		return nil, text.errorf(len(lines), 0, "invalid maze string: too few lines")
+	}
+
+	// Calculate dimensions
+	width := (len(lines[0]) - 1) / 3 // Each cell is 3 chars wide including walls
+	height := (len(lines) - 1) / 2   // Each cell is 2 lines tall including walls
+
+	if width < 1 || height < 1 {
+		return nil, text.errorf(0, 0, "invalid maze dimensions: width=%d, height=%d", width, height)
+	}
+
+	// Every line of the maze is as long as the top border
+	length := width*3 + 1
+	for i, line := range lines[:height*2+1] {
+		if o.Lenient {
+			if extra := line[min(length, len(line)):]; strings.TrimSpace(extra) == "" {
+				line = line[:len(line)-len(extra)]
+			}
+			line += strings.Repeat(" ", max(0, length-len(line)))
+			lines[i] = line
+		}
+		if len(line) != length {
+			return nil, text.errorf(i, min(length, len(line)), "invalid line length %d, want %d", len(line), length)
+		}
+	}
+	if o.Strict {
+		if err := text.checkWalls(width, height); err != nil {
+			return nil, err
+		}
+	}
+
+	maze := NewMaze(width, height)
+
+	// Read the markers first, so that walls next to masked out cells are
+	// left alone
+	markers, err := text.parseCellMarkers(width, height)
+	if err != nil {
+		return nil, err
+	}
+	markers.applyMask(maze)
+
+	// Process each cell
+	for y := 0; y < height; y++ {
+		// Check vertical walls (in the cell content line)
+		cellLine := lines[y*2+1]
+		for x := 0; x < width; x++ {
+			// Check west wall
+			if x == 0 {
+				if cellLine[0] != '|' {
+					maze.RemoveWall(x, y, West)
+				}
+			}
+
+			// Check east wall
+			if cellLine[x*3+3] == ' ' {
+				maze.RemoveWall(x, y, East)
+			}
+		}
+
+		// Check horizontal walls (in the wall line)
+		wallLine := lines[y*2+2]
+		for x := 0; x < width; x++ {
+			// Check south wall
+			if wallLine[x*3+1:x*3+3] == "  " {
+				maze.RemoveWall(x, y, South)
+			}
+		}
+
+		// Check north walls for first row
+		if y == 0 {
+			topLine := lines[0]
+			for x := 0; x < width; x++ {
+				if topLine[x*3+1:x*3+3] == "  " {
+					maze.RemoveWall(x, y, North)
+				}
+			}
+		}
+	}
+
+	// A single opening in the outer wall is the exit, unless a marker says
+	// otherwise
+	if exits := maze.Exits(); len(exits) == 1 {
+		maze.Exit = &exits[0]
+	}
+	if err := markers.apply(maze, text); err != nil {
+		return nil, err
+	}
+
+	return maze, nil
+}
+
+// checkWalls makes sure that every character outside the cells is a corner
+// or a wall drawn the way String draws them, and that nothing follows the
+// bottom border
+func (t mazeText) checkWalls(width, height int) error {
+	for i, line := range t.lines[:height*2+1] {
+		for x := 0; x <= width; x++ {
+			if i%2 == 1 {
+				if c := line[x*3]; c != '|' && c != ' ' {
+					return t.errorf(i, x*3, "invalid wall %q, want '|' or ' '", c)
+				}
+				continue
+			}
+			if c := line[x*3]; c != '+' {
+				return t.errorf(i, x*3, "invalid corner %q, want '+'", c)
+			}
+			if x == width {
+				continue
+			}
+			if wall := line[x*3+1 : x*3+3]; wall != "--" && wall != "  " {
+				return t.errorf(i, x*3+1, "invalid wall %q, want \"--\" or \"  \"", wall)
+			}
+		}
+	}
+	if len(t.lines) > height*2+1 {
+		return t.errorf(height*2+1, 0, "unexpected line after the bottom border")
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStrict(t *testing.T) {
+	maze, err := ParseOptions{Strict: true}.Parse(solverMaze)
+	require.NoError(t, err)
+	legacy, err := ParseMaze(solverMaze)
+	require.NoError(t, err)
+	assertSameMaze(t, legacy, maze)
+
+	tests := []struct {
+		name         string
+		input        string
+		line, column int
+		snippet      string
+	}{
+		{
+			name:    "bad corner",
+			input:   "+--+--+\n|     |\n+--*--+",
+			line:    3,
+			column:  4,
+			snippet: "+--*--+",
+		},
+		{
+			name:    "half a wall",
+			input:   "+--+--+\n|     |\n+--+- +",
+			line:    3,
+			column:  5,
+			snippet: "+--+- +",
+		},
+		{
+			name:    "bad vertical wall",
+			input:   "+--+--+\n|  !  |\n+--+--+",
+			line:    2,
+			column:  4,
+			snippet: "|  !  |",
+		},
+		{
+			name:    "extra line",
+			input:   "+--+\n|  |\n+--+\n|  |",
+			line:    4,
+			column:  1,
+			snippet: "|  |",
+		},
+		{
+			name:    "blank lines before the maze",
+			input:   "\n\n+--+\n|  |\n+-+-",
+			line:    5,
+			column:  2,
+			snippet: "+-+-",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOptions{Strict: true}.Parse(tt.input)
+			var parseErr *ParseError
+			require.True(t, errors.As(err, &parseErr), "got %v", err)
+			assert.Equal(t, tt.line, parseErr.Line)
+			assert.Equal(t, tt.column, parseErr.Column)
+			assert.Equal(t, tt.snippet, parseErr.Snippet)
+
+			_, err = ParseMaze(tt.input)
+			assert.NoError(t, err, "the default mode reads anything that is not blank as a wall")
+		})
+	}
+}
+
+func TestParseErrorLocations(t *testing.T) {
+	_, err := ParseMaze("+--+--+\n|  |\n+--+--+")
+	var parseErr *ParseError
+	require.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, &ParseError{Line: 2, Column: 5, Snippet: "|  |", Msg: "invalid line length 4, want 7"}, parseErr)
+	assert.Equal(t, `line 2, column 5: invalid line length 4, want 7: "|  |"`, err.Error())
+
+	_, err = ParseOptions{Strict: true}.Parse("\n  +--+--*\n|     |\n+--+--+")
+	require.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 2, parseErr.Line)
+	assert.Equal(t, 9, parseErr.Column, "columns count from the start of the line, not of the maze")
+
+	_, err = ParseMaze("+--+")
+	require.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 2, parseErr.Line, "missing lines point just after the input")
+}
+
+func TestParseLenient(t *testing.T) {
+	// The exit opens the east border, so a line ends in a blank
+	maze, _ := MazeConfig{Width: 5, Height: 4, Seed: 7, Exit: &Exit{Position: Position{4, 2}, Direction: East}}.Generate()
+	maze.Seed, maze.Generator = 0, ""
+	text := maze.MarkedString()
+
+	// Windows line endings, and an editor stripping or adding whitespace
+	inputs := map[string]string{
+		"crlf": strings.ReplaceAll(text, "\n", "\r\n"),
+		"stripped": func() string {
+			lines := strings.Split(text, "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight(line, " ")
+			}
+			return strings.Join(lines, "\n")
+		}(),
+		"padded": strings.ReplaceAll(text, "\n", "  \t\n"),
+	}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseMaze(input)
+			assert.Error(t, err)
+
+			parsed, err := ParseOptions{Strict: true, Lenient: true}.Parse(input)
+			require.NoError(t, err)
+			assertSameMaze(t, maze, parsed)
+		})
+	}
+
+	_, err := ParseOptions{Lenient: true}.Parse("+--+\n|  | x\n+--+")
+	assert.Error(t, err, "only whitespace may trail")
+}
@@ -0,0 +1,308 @@
+package game
+
+import "math/rand"
+
+// Placement is a strategy for choosing where the player starts and where
+// the exit is
+type Placement int
+
+const (
+	// PlacementRandom picks the start and the exit independently at random
+	PlacementRandom Placement = iota
+	// PlacementFarthest puts the start and the exit as far apart as the
+	// passages allow
+	PlacementFarthest
+	// PlacementCorner starts in the top-left corner and exits through the
+	// east wall of the bottom-right corner, or its south wall on grids
+	// where cells have no east wall. In masked mazes it uses the first and
+	// last cells instead, row by row
+	PlacementCorner
+	// PlacementMinDistance picks at random, but keeps the route from the
+	// start out of the maze at least MazeConfig.MinDistance moves long
+	PlacementMinDistance
+)
+
+func (p Placement) String() string {
+	switch p {
+	case PlacementRandom:
+		return "Random"
+	case PlacementFarthest:
+		return "Farthest"
+	case PlacementCorner:
+		return "Corner"
+	case PlacementMinDistance:
+		return "Min Distance"
+	default:
+		return "Unknown"
+	}
+}
+
+// maxPlacementAttempts bounds how many random exits PlacementMinDistance
+// tries before settling for PlacementFarthest
+const maxPlacementAttempts = 20
+
+// place chooses the start and the exit of a carved maze, honoring the
+// config's overrides where they fit the maze
+func (c MazeConfig) place(m *Maze, randomStart Position, rng *rand.Rand) (Position, Exit) {
+	start, exit := c.Start, c.Exit
+	if start != nil && !m.canStart(*start) {
+		start = nil
+	}
+	if exit != nil && !m.isBoundaryWall(*exit) {
+		exit = nil
+	}
+	switch {
+	case start != nil && exit != nil:
+		return *start, *exit
+	case start != nil:
+		return *start, c.exitFor(m, *start, rng)
+	case exit != nil:
+		return c.startFor(m, *exit, randomStart, rng), *exit
+	}
+
+	switch c.Placement {
+	case PlacementFarthest:
+		return farthestPair(m)
+	case PlacementCorner:
+		return m.firstCell(), cornerExit(m)
+	case PlacementMinDistance:
+		for attempt := 0; attempt < maxPlacementAttempts; attempt++ {
+			exit := randomExit(m, rng)
+			if start, ok := randomStartAtLeast(m, exit, c.MinDistance, rng); ok {
+				return start, exit
+			}
+		}
+		return farthestPair(m)
+	default:
+		return randomStart, randomExit(m, rng)
+	}
+}
+
+// exitFor chooses an exit to go with a start that was fixed in advance
+func (c MazeConfig) exitFor(m *Maze, start Position, rng *rand.Rand) Exit {
+	switch c.Placement {
+	case PlacementFarthest:
+		return farthestExit(m, m.DistanceMap(start))
+	case PlacementCorner:
+		return cornerExit(m)
+	case PlacementMinDistance:
+		distances := m.DistanceMap(start)
+		var candidates []Exit
+		for _, exit := range boundaryWalls(m) {
+			if d := distances[exit.Position.Y][exit.Position.X]; d >= 0 && d+1 >= c.MinDistance {
+				candidates = append(candidates, exit)
+			}
+		}
+		if len(candidates) == 0 {
+			return farthestExit(m, distances)
+		}
+		return candidates[rng.Intn(len(candidates))]
+	default:
+		return randomExit(m, rng)
+	}
+}
+
+// startFor chooses a start to go with an exit that was fixed in advance
+func (c MazeConfig) startFor(m *Maze, exit Exit, randomStart Position, rng *rand.Rand) Position {
+	switch c.Placement {
+	case PlacementFarthest:
+		start, _ := farthestCell(m.DistanceMap(exit.Position))
+		return start
+	case PlacementCorner:
+		return m.firstCell()
+	case PlacementMinDistance:
+		if start, ok := randomStartAtLeast(m, exit, c.MinDistance, rng); ok {
+			return start
+		}
+		start, _ := farthestCell(m.DistanceMap(exit.Position))
+		return start
+	default:
+		return randomStart
+	}
+}
+
+// canStart reports whether the player can start at the position: a cell of
+// the maze that is not a crossing
+func (m *Maze) canStart(p Position) bool {
+	return m.IsValidPosition(p.X, p.Y) && !m.isCrossing(p.X, p.Y)
+}
+
+// isBoundaryWall reports whether the exit is a wall on the outside of the
+// maze, one of those boundaryWalls returns
+func (m *Maze) isBoundaryWall(exit Exit) bool {
+	p := exit.Position
+	return m.IsValidPosition(p.X, p.Y) && m.hasDirection(p.X, p.Y, exit.Direction) &&
+		!m.IsValidPosition(m.Neighbor(p.X, p.Y, exit.Direction))
+}
+
+// boundaryWalls returns every wall on the outside of the maze, that is,
+// every place where an exit could go, row by row
+func boundaryWalls(m *Maze) []Exit {
+	var walls []Exit
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if !m.IsValidPosition(x, y) {
+				continue
+			}
+			for _, dir := range m.Directions(x, y) {
+				if !m.IsValidPosition(m.Neighbor(x, y, dir)) {
+					walls = append(walls, Exit{Position: Position{X: x, Y: y}, Direction: dir})
+				}
+			}
+		}
+	}
+	return walls
+}
+
+// randomExit picks an edge of the maze at random, then a cell along it. In
+// masked mazes, and in mazes that are not square grids, it picks any wall
+// on the outside
+func randomExit(m *Maze, rng *rand.Rand) Exit {
+	if m.Mask != nil || !m.isSquare() {
+		walls := boundaryWalls(m)
+		return walls[rng.Intn(len(walls))]
+	}
+
+	switch rng.Intn(4) {
+	case 0: // North edge
+		return Exit{Position: Position{X: rng.Intn(m.Width), Y: 0}, Direction: North}
+	case 1: // South edge
+		return Exit{Position: Position{X: rng.Intn(m.Width), Y: m.Height - 1}, Direction: South}
+	case 2: // East edge
+		return Exit{Position: Position{X: m.Width - 1, Y: rng.Intn(m.Height)}, Direction: East}
+	default: // West edge
+		return Exit{Position: Position{X: 0, Y: rng.Intn(m.Height)}, Direction: West}
+	}
+}
+
+// cornerExit is the exit through the east wall of the last cell, the
+// bottom-right corner unless the maze is masked, or through its south wall
+// where there is no east one. Cells with neither, which no built-in grid
+// has, exit through their first outer wall
+func cornerExit(m *Maze) Exit {
+	last := m.lastCell()
+	for _, dir := range []MazeDirection{East, South} {
+		if exit := (Exit{Position: last, Direction: dir}); m.isBoundaryWall(exit) {
+			return exit
+		}
+	}
+	walls := boundaryWalls(m)
+	for _, wall := range walls {
+		if wall.Position == last {
+			return wall
+		}
+	}
+	return walls[len(walls)-1]
+}
+
+// placeGoal chooses the start and the goal cell of a carved maze that has
+// no outer wall, following the same strategies as place
+func (c MazeConfig) placeGoal(m *Maze, randomStart Position, rng *rand.Rand) (Position, Position) {
+	start := randomStart
+	switch {
+	case c.Start != nil && m.canStart(*c.Start):
+		start = *c.Start
+	case c.Placement == PlacementFarthest:
+		start, _ = farthestCell(m.DistanceMap(m.firstCell()))
+	case c.Placement == PlacementCorner:
+		start = m.firstCell()
+	}
+
+	distances := m.DistanceMap(start)
+	switch c.Placement {
+	case PlacementFarthest:
+		goal, _ := farthestCell(distances)
+		return start, goal
+	case PlacementCorner:
+		return start, m.lastCell()
+	case PlacementMinDistance:
+		var candidates []Position
+		for y, row := range distances {
+			for x, d := range row {
+				if d >= c.MinDistance && d > 0 {
+					candidates = append(candidates, Position{X: x, Y: y})
+				}
+			}
+		}
+		if len(candidates) == 0 {
+			goal, _ := farthestCell(distances)
+			return start, goal
+		}
+		return start, candidates[rng.Intn(len(candidates))]
+	default:
+		goal := m.randomCell(rng)
+		for (goal == start || distances[goal.Y][goal.X] < 0) && m.cellCount() > 1 {
+			goal = m.randomCell(rng)
+		}
+		return start, goal
+	}
+}
+
+// randomStartAtLeast picks a random start whose way out through the exit
+// takes at least minDistance moves
+func randomStartAtLeast(m *Maze, exit Exit, minDistance int, rng *rand.Rand) (Position, bool) {
+	distances := m.DistanceMap(exit.Position)
+	var candidates []Position
+	for y, row := range distances {
+		for x, d := range row {
+			if d >= 0 && d+1 >= minDistance {
+				candidates = append(candidates, Position{X: x, Y: y})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return Position{}, false
+	}
+	return candidates[rng.Intn(len(candidates))], true
+}
+
+// farthestCell returns the cell with the largest distance in the map
+func farthestCell(distances [][]int) (Position, int) {
+	var best Position
+	bestDistance := -1
+	for y, row := range distances {
+		for x, d := range row {
+			if d > bestDistance {
+				best, bestDistance = Position{X: x, Y: y}, d
+			}
+		}
+	}
+	return best, bestDistance
+}
+
+// farthestExit returns the outer wall farthest from wherever the distance
+// map was measured from
+func farthestExit(m *Maze, distances [][]int) Exit {
+	var best Exit
+	bestDistance := -1
+	for _, exit := range boundaryWalls(m) {
+		if d := distances[exit.Position.Y][exit.Position.X]; d > bestDistance {
+			best, bestDistance = exit, d
+		}
+	}
+	return best
+}
+
+// farthestPair finds the start and exit with the longest route between
+// them. In a perfect maze the cell farthest from any other cell is always
+// one of the two ends of the longest path, so measuring from both ends is
+// enough; in braided mazes the result is a close approximation
+func farthestPair(m *Maze) (Position, Exit) {
+	a, _ := farthestCell(m.DistanceMap(m.firstCell()))
+	fromA := m.DistanceMap(a)
+	b, _ := farthestCell(fromA)
+	fromB := m.DistanceMap(b)
+
+	var bestExit Exit
+	bestStart, bestDistance := a, -1
+	for _, exit := range boundaryWalls(m) {
+		p := exit.Position
+		if d := fromA[p.Y][p.X]; d > bestDistance {
+			bestExit, bestStart, bestDistance = exit, a, d
+		}
+		if d := fromB[p.Y][p.X]; d > bestDistance {
+			bestExit, bestStart, bestDistance = exit, b, d
+		}
+	}
+	return bestStart, bestExit
+}
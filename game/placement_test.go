@@ -0,0 +1,150 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// routeLength returns the number of moves from the maze's start out
+// through its exit
+func routeLength(t *testing.T, maze *Maze) int {
+	t.Helper()
+	require.NotNil(t, maze.Exit)
+	require.Equal(t, []Exit{*maze.Exit}, maze.Exits(), "the exit should be the only opening")
+	return maze.DistanceMap(maze.Start)[maze.Exit.Position.Y][maze.Exit.Position.X] + 1
+}
+
+func TestPlacement(t *testing.T) {
+	t.Run("random", func(t *testing.T) {
+		maze, start := MazeConfig{Width: 10, Height: 10, Seed: 1}.Generate()
+		assert.Equal(t, maze.Start, start)
+		assert.Positive(t, routeLength(t, maze))
+	})
+
+	t.Run("corner", func(t *testing.T) {
+		maze, start := MazeConfig{Width: 10, Height: 8, Seed: 1, Placement: PlacementCorner}.Generate()
+		assert.Equal(t, Position{0, 0}, start)
+		assert.Equal(t, &Exit{Position: Position{9, 7}, Direction: East}, maze.Exit)
+
+		// The same side on every grid that has it, south on the others
+		sides := map[Grid]MazeDirection{GridHex: South, GridTriangle: East, GridPolar: South}
+		for grid, side := range sides {
+			maze, _ := MazeConfig{Width: 8, Height: 6, Seed: 1, Grid: grid, Placement: PlacementCorner}.Generate()
+			require.NotNil(t, maze.Exit, grid.String())
+			assert.Equal(t, side, maze.Exit.Direction, grid.String())
+			assert.Equal(t, maze.lastCell(), maze.Exit.Position, grid.String())
+		}
+	})
+
+	t.Run("farthest beats every other choice", func(t *testing.T) {
+		for seed := int64(0); seed < 10; seed++ {
+			maze, _ := MazeConfig{Width: 9, Height: 7, Seed: seed, Placement: PlacementFarthest}.Generate()
+			got := routeLength(t, maze)
+
+			// Brute force over every start and boundary cell
+			want := 0
+			for y := 0; y < maze.Height; y++ {
+				for x := 0; x < maze.Width; x++ {
+					distances := maze.DistanceMap(Position{x, y})
+					for _, exit := range boundaryWalls(maze) {
+						want = max(want, distances[exit.Position.Y][exit.Position.X]+1)
+					}
+				}
+			}
+			assert.Equal(t, want, got, "seed %d", seed)
+		}
+	})
+
+	t.Run("min distance", func(t *testing.T) {
+		for seed := int64(0); seed < 20; seed++ {
+			maze, _ := MazeConfig{Width: 10, Height: 10, Seed: seed, Placement: PlacementMinDistance, MinDistance: 15}.Generate()
+			assert.GreaterOrEqual(t, routeLength(t, maze), 15, "seed %d", seed)
+		}
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		start := Position{3, 4}
+		exit := Exit{Position: Position{0, 2}, Direction: West}
+
+		maze, got := MazeConfig{Width: 6, Height: 6, Seed: 1, Start: &start, Exit: &exit}.Generate()
+		assert.Equal(t, start, got)
+		assert.Equal(t, &exit, maze.Exit)
+
+		maze, got = MazeConfig{Width: 6, Height: 6, Seed: 1, Placement: PlacementFarthest, Exit: &exit}.Generate()
+		assert.Equal(t, &exit, maze.Exit)
+		_, farthest := farthestCell(maze.DistanceMap(exit.Position))
+		assert.Equal(t, farthest+1, routeLength(t, maze))
+		assert.Equal(t, maze.Start, got)
+
+		maze, _ = MazeConfig{Width: 6, Height: 6, Seed: 1, Placement: PlacementMinDistance, MinDistance: 8, Start: &start}.Generate()
+		assert.Equal(t, start, maze.Start)
+		assert.GreaterOrEqual(t, routeLength(t, maze), 8)
+	})
+
+	t.Run("overrides that do not fit the maze", func(t *testing.T) {
+		mask, err := ParseMask(ringMask)
+		require.NoError(t, err)
+		weave, _ := MazeConfig{Width: 8, Height: 8, Seed: 1, Generator: WeaveGenerator{Density: 1}}.Generate()
+		var crossing *Position
+		for y := 0; y < weave.Height && crossing == nil; y++ {
+			for x := 0; x < weave.Width; x++ {
+				if weave.isCrossing(x, y) {
+					crossing = &Position{x, y}
+					break
+				}
+			}
+		}
+		require.NotNil(t, crossing)
+
+		starts := map[string]MazeConfig{
+			"outside":    {Width: 5, Height: 5, Start: &Position{9, 9}},
+			"masked out": {Mask: mask, Start: &Position{0, 0}},
+			"crossing":   {Width: 8, Height: 8, Generator: WeaveGenerator{Density: 1}, Start: crossing},
+		}
+		exits := map[string]MazeConfig{
+			"inside":       {Width: 5, Height: 5, Exit: &Exit{Position{2, 2}, East}},
+			"outside":      {Width: 5, Height: 5, Exit: &Exit{Position{7, 0}, East}},
+			"no such side": {Width: 5, Height: 5, Exit: &Exit{Position{4, 0}, NorthEast}},
+			"masked out":   {Mask: mask, Exit: &Exit{Position{0, 0}, North}},
+		}
+		check := func(t *testing.T, config MazeConfig) {
+			maze, start := config.Generate()
+			assert.NoError(t, maze.Validate(start).Err(), "the placement strategy takes over")
+		}
+		for name, config := range starts {
+			t.Run("start "+name, func(t *testing.T) {
+				config.Seed = 1
+				check(t, config)
+				maze, _ := config.Generate()
+				assert.NotEqual(t, *config.Start, maze.Start)
+			})
+		}
+		for name, config := range exits {
+			t.Run("exit "+name, func(t *testing.T) {
+				config.Seed = 1
+				check(t, config)
+				maze, _ := config.Generate()
+				assert.NotEqual(t, config.Exit, maze.Exit)
+			})
+		}
+
+		// Both at once, as reported
+		start, exit := Position{9, 9}, Exit{Position{2, 2}, East}
+		check(t, MazeConfig{Width: 5, Height: 5, Seed: 1, Start: &start, Exit: &exit})
+
+		torus := MazeConfig{Width: 5, Height: 5, Seed: 1, Grid: GridTorus, Start: &Position{9, 9}}
+		check(t, torus)
+	})
+}
+
+func TestParseMazeExit(t *testing.T) {
+	maze, err := ParseMaze(solverMaze)
+	require.NoError(t, err)
+	assert.Equal(t, &Exit{Position: Position{2, 1}, Direction: East}, maze.Exit)
+
+	maze, err = ParseMaze(NewMaze(2, 2).String())
+	require.NoError(t, err)
+	assert.Nil(t, maze.Exit)
+}
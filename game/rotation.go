@@ -0,0 +1,183 @@
+package game
+
+// Release is one button release in a plan: the player, standing at
+// Position, lets go of the button on the given tick and moves in Direction
+type Release struct {
+	Tick      int
+	Position  Position
+	Direction MazeDirection
+}
+
+// RotationSolution is the fastest way out of a maze for a player whose
+// direction keeps rotating, as in MazeScreen
+type RotationSolution struct {
+	// Ticks is the tick on which the winning release happens
+	Ticks int
+	// Presses is how many times the button is pressed in the fastest plan;
+	// among equally fast plans, the one with the fewest presses is chosen
+	Presses int
+	// MinPresses is the fewest presses any plan needs, however slow it is
+	MinPresses int
+	// Releases is the fastest plan, one entry per press
+	Releases []Release
+}
+
+// SolveRotating finds the fastest way out of the maze, or to its goal, from
+// the start position when, as in MazeScreen, the player's direction starts
+// at the first of the cell's directions, North on a square grid, and
+// rotates clockwise through them every ticksPerRotation ticks, and the
+// player moves one cell in the current direction when the button is
+// released. Ticks are numbered from 1, the first update of the screen.
+// Since a release needs a press on an earlier tick, the first release can
+// happen on tick 2, and releases are at least two ticks apart.
+//
+// The search explores every combination of cell, direction and rotation
+// timer, so it is meant for game sized mazes. It returns false if no exit
+// is reachable from the start, and an empty plan if the start is the goal.
+func (m *Maze) SolveRotating(start Position, ticksPerRotation int) (RotationSolution, bool) {
+	shortest, ok := m.ShortestPath(start)
+	if !ok {
+		return RotationSolution{}, false
+	}
+	if m.IsGoal(start) {
+		return RotationSolution{}, true // Won before the first tick, nothing to do
+	}
+	if ticksPerRotation < 1 {
+		ticksPerRotation = 1
+	}
+
+	// A state is a cell, the direction the player faces as an index into
+	// the cell's directions, the ticks since the last rotation and whether
+	// the button was released on the tick before, flattened into a single
+	// index
+	directions := m.directionCount()
+	stateIndex := func(cell Position, dir, timer int, cooling bool) int {
+		i := ((m.cellIndex(cell)*directions+dir)*ticksPerRotation + timer) * 2
+		if cooling {
+			i++
+		}
+		return i
+	}
+	stateCount := m.Width * m.Height * directions * ticksPerRotation * 2
+
+	ticks := make([]int32, stateCount)
+	presses := make([]int32, stateCount)
+	parent := make([]int32, stateCount)
+	for i := range ticks {
+		ticks[i] = -1
+	}
+
+	type state struct {
+		cell    Position
+		dir     int
+		timer   int
+		cooling bool
+	}
+	decode := func(i int) state {
+		s := state{cooling: i%2 == 1}
+		i /= 2
+		s.timer = i % ticksPerRotation
+		i /= ticksPerRotation
+		s.dir = i % directions
+		i /= directions
+		s.cell = Position{X: i % m.Width, Y: i / m.Width}
+		return s
+	}
+
+	// Nobody can release on the first tick, so start as if cooling down
+	first := stateIndex(start, 0, 0, true)
+	ticks[first] = 0
+	parent[first] = -1
+
+	// rotate advances the rotation timer by a tick, as MazeScreen.Update does
+	rotate := func(cell Position, dir, timer int) (int, int) {
+		timer++
+		if timer >= ticksPerRotation {
+			return (dir + 1) % len(m.Directions(cell.X, cell.Y)), 0
+		}
+		return dir, timer
+	}
+	// facing returns the direction the player faces in a state
+	facing := func(cell Position, dir int) MazeDirection {
+		return m.Directions(cell.X, cell.Y)[dir]
+	}
+
+	bestTicks, bestPresses, bestFinal := -1, 0, -1
+	visit := func(from, to int, released bool) {
+		t := ticks[from] + 1
+		p := presses[from]
+		if released {
+			p++
+		}
+		switch {
+		case ticks[to] < 0:
+			ticks[to] = t
+		case ticks[to] == t && p < presses[to]:
+		default:
+			return
+		}
+		presses[to] = p
+		parent[to] = int32(from)
+	}
+
+	queue := []int{first}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if bestTicks >= 0 && int(ticks[current]) >= bestTicks {
+			break // Everything left is at least as slow
+		}
+		s := decode(current)
+		dir, timer := rotate(s.cell, s.dir, s.timer)
+
+		// Either keep holding (or not pressing) the button...
+		wait := stateIndex(s.cell, dir, timer, false)
+		if ticks[wait] < 0 {
+			queue = append(queue, wait)
+		}
+		visit(current, wait, false)
+
+		// ...or release it and move
+		if s.cooling {
+			continue
+		}
+		next, ok := m.Move(s.cell, facing(s.cell, dir))
+		if !ok {
+			continue // Bumping into a wall never helps
+		}
+		if !m.IsValidPosition(next.X, next.Y) || m.IsGoal(next) {
+			t, p := int(ticks[current])+1, int(presses[current])+1
+			if bestTicks < 0 || p < bestPresses {
+				bestTicks, bestPresses, bestFinal = t, p, current
+			}
+			continue
+		}
+		// Cells with fewer walls keep the player facing the same way round
+		moved := stateIndex(next, dir%len(m.Directions(next.X, next.Y)), timer, true)
+		if ticks[moved] < 0 {
+			queue = append(queue, moved)
+		}
+		visit(current, moved, true)
+	}
+
+	// Walk the parents back to recover the releases
+	final := decode(bestFinal)
+	finalDir, _ := rotate(final.cell, final.dir, final.timer)
+	releases := []Release{{Tick: bestTicks, Position: final.cell, Direction: facing(final.cell, finalDir)}}
+	for i := bestFinal; parent[i] >= 0; i = int(parent[i]) {
+		if s, prev := decode(i), decode(int(parent[i])); s.cell != prev.cell {
+			dir, _ := rotate(prev.cell, prev.dir, prev.timer)
+			releases = append(releases, Release{Tick: int(ticks[i]), Position: prev.cell, Direction: facing(prev.cell, dir)})
+		}
+	}
+	for i, j := 0, len(releases)-1; i < j; i, j = i+1, j-1 {
+		releases[i], releases[j] = releases[j], releases[i]
+	}
+
+	return RotationSolution{
+		Ticks:      bestTicks,
+		Presses:    bestPresses,
+		MinPresses: shortest.Len(),
+		Releases:   releases,
+	}, true
+}
@@ -0,0 +1,119 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolveRotating(t *testing.T) {
+	t.Run("exit ahead of the first rotation", func(t *testing.T) {
+		maze := NewMaze(1, 1)
+		maze.RemoveWall(0, 0, East)
+
+		// Facing East on ticks 2 and 3, and tick 2 is the first chance to release
+		solution, ok := maze.SolveRotating(Position{0, 0}, 2)
+		require.True(t, ok)
+		assert.Equal(t, RotationSolution{
+			Ticks:      2,
+			Presses:    1,
+			MinPresses: 1,
+			Releases:   []Release{{Tick: 2, Position: Position{0, 0}, Direction: East}},
+		}, solution)
+	})
+
+	t.Run("waiting for a full turn", func(t *testing.T) {
+		maze := NewMaze(1, 1)
+		maze.RemoveWall(0, 0, North)
+
+		// North on ticks 0 and 1 is too early, it comes back on tick 8
+		solution, ok := maze.SolveRotating(Position{0, 0}, 2)
+		require.True(t, ok)
+		assert.Equal(t, 8, solution.Ticks)
+		assert.Equal(t, 1, solution.Presses)
+	})
+
+	t.Run("releases must be two ticks apart", func(t *testing.T) {
+		maze := NewMaze(2, 1)
+		maze.RemoveWall(0, 0, East)
+		maze.RemoveWall(1, 0, East)
+
+		// East on ticks 3 to 5: release on 3, then again on 5
+		solution, ok := maze.SolveRotating(Position{0, 0}, 3)
+		require.True(t, ok)
+		assert.Equal(t, RotationSolution{
+			Ticks:      5,
+			Presses:    2,
+			MinPresses: 2,
+			Releases: []Release{
+				{Tick: 3, Position: Position{0, 0}, Direction: East},
+				{Tick: 5, Position: Position{1, 0}, Direction: East},
+			},
+		}, solution)
+	})
+
+	t.Run("starting on the goal", func(t *testing.T) {
+		maze := NewMaze(2, 2)
+		maze.Goal = &Position{1, 1}
+
+		solution, ok := maze.SolveRotating(Position{1, 1}, 2)
+		require.True(t, ok)
+		assert.Equal(t, RotationSolution{}, solution)
+	})
+
+	t.Run("no way out", func(t *testing.T) {
+		_, ok := NewMaze(2, 2).SolveRotating(Position{0, 0}, 2)
+		assert.False(t, ok)
+	})
+}
+
+// simulateReleases replays releases the way MazeScreen.Update handles input,
+// and returns the tick on which the player left the maze, or -1
+func simulateReleases(maze *Maze, start Position, ticksPerRotation int, releases []Release) int {
+	releaseAt := make(map[int]bool)
+	last := 0
+	for _, r := range releases {
+		releaseAt[r.Tick] = true
+		last = max(last, r.Tick)
+	}
+
+	p, dir, timer := start, North, 0
+	for tick := 1; tick <= last; tick++ {
+		timer++
+		if timer >= ticksPerRotation {
+			dir = MazeDirection((int(dir) + 1) % 4)
+			timer = 0
+		}
+		if !releaseAt[tick] {
+			continue
+		}
+		next, ok := maze.Move(p, dir)
+		if ok && !maze.IsValidPosition(next.X, next.Y) {
+			return tick
+		}
+		if ok {
+			p = next
+		}
+	}
+	return -1
+}
+
+func TestSolveRotatingPlansWork(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		maze, start := MazeConfig{Width: 8, Height: 8, Seed: seed, Braid: 0.3}.Generate()
+		ticksPerRotation := 1 + rng.Intn(20)
+
+		solution, ok := maze.SolveRotating(start, ticksPerRotation)
+		require.True(t, ok)
+		assert.Len(t, solution.Releases, solution.Presses)
+		assert.GreaterOrEqual(t, solution.Presses, solution.MinPresses)
+		assert.Equal(t, solution.Ticks, simulateReleases(maze, start, ticksPerRotation, solution.Releases), "seed %d", seed)
+		for i := 1; i < len(solution.Releases); i++ {
+			// The button has to be pressed again on a tick in between
+			assert.GreaterOrEqual(t, solution.Releases[i].Tick-solution.Releases[i-1].Tick, 2)
+		}
+	}
+}
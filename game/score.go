@@ -0,0 +1,66 @@
+package game
+
+import (
+	"math"
+	"time"
+)
+
+// RunStats counts what the player did on one run through a maze
+type RunStats struct {
+	// Ticks is how many updates the run took, at TPS updates a second
+	Ticks int
+	TPS   int
+	// Presses is how many times the button was pressed. Each press is
+	// either a move or a bump
+	Presses int
+	// Moves is how many presses took the player to another cell, or out
+	Moves int
+	// Bumps is how many presses ran the player into a wall
+	Bumps int
+	// MissedRotations is how many times the player's direction came round
+	// to the shortest way out and rotated on past it
+	MissedRotations int
+}
+
+// Elapsed returns how long the run took
+func (r RunStats) Elapsed() time.Duration {
+	if r.TPS <= 0 {
+		return 0
+	}
+	return time.Duration(r.Ticks) * time.Second / time.Duration(r.TPS)
+}
+
+// Score turns a won run through a maze of the given number of cells at
+// the given speed into points. Bigger mazes and faster speeds are worth
+// more. The full amount is kept by runs that take no longer than waiting
+// for two rotations in every cell of the maze, slower ones keep a share
+// down to a quarter. Every bump then takes away 5% of it and every missed
+// rotation 2%, leaving at least a tenth
+func (r RunStats) Score(cells int, speed PlayerSpeed) int {
+	base := 100 * float64(cells) * speedMultiplier(speed)
+
+	timeFactor := 1.0
+	if r.TPS > 0 && r.Ticks > 0 {
+		par := float64(2 * cells * speed.RotationTicks(r.TPS))
+		timeFactor = math.Max(0.25, math.Min(1, par/float64(r.Ticks)))
+	}
+
+	accuracy := math.Max(0.1, 1-0.05*float64(r.Bumps)-0.02*float64(r.MissedRotations))
+
+	return int(math.Round(base * timeFactor * accuracy))
+}
+
+// speedMultiplier is how much more a maze is worth at the speed, for the
+// timing it takes to catch a direction that does not hold for long
+func speedMultiplier(speed PlayerSpeed) float64 {
+	switch speed {
+	case SpeedLow:
+		return 1
+	case SpeedMedium:
+		return 1.5
+	case SpeedHigh:
+		return 2
+	default:
+		return 1
+	}
+}
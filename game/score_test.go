@@ -0,0 +1,34 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunStatsElapsed(t *testing.T) {
+	assert.Equal(t, 1500*time.Millisecond, RunStats{Ticks: 90, TPS: 60}.Elapsed())
+	assert.Zero(t, RunStats{Ticks: 90}.Elapsed(), "no TPS, no time")
+}
+
+func TestRunStatsScore(t *testing.T) {
+	// A 3x3 maze at low speed and 60 TPS has a par of 2*9*60 ticks
+	perfect := RunStats{Ticks: 200, TPS: 60, Presses: 4, Moves: 4}
+	assert.Equal(t, 900, perfect.Score(9, SpeedLow))
+	assert.Equal(t, 1800, perfect.Score(9, SpeedHigh), "faster speeds are worth more")
+	assert.Greater(t, perfect.Score(16, SpeedLow), perfect.Score(9, SpeedLow), "bigger mazes are worth more")
+
+	slow := perfect
+	slow.Ticks = 2 * 1080
+	assert.Equal(t, 450, slow.Score(9, SpeedLow), "twice the par keeps half")
+	slow.Ticks = 100 * 1080
+	assert.Equal(t, 225, slow.Score(9, SpeedLow), "slow runs keep a quarter")
+
+	sloppy := perfect
+	sloppy.Bumps = 2
+	sloppy.MissedRotations = 5
+	assert.Equal(t, 720, sloppy.Score(9, SpeedLow))
+	sloppy.Bumps = 100
+	assert.Equal(t, 90, sloppy.Score(9, SpeedLow), "at least a tenth is left")
+}
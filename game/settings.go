@@ -0,0 +1,262 @@
+package game
+
+type PlayerSpeed int
+
+const (
+	SpeedLow PlayerSpeed = iota
+	SpeedMedium
+	SpeedHigh
+
+	playerSpeedCount = int(SpeedHigh) + 1
+)
+
+// Next returns the speed after this one, wrapping round to the first
+func (s PlayerSpeed) Next() PlayerSpeed {
+	return PlayerSpeed((int(s) + 1) % playerSpeedCount)
+}
+
+func (s PlayerSpeed) String() string {
+	switch s {
+	case SpeedLow:
+		return "Low"
+	case SpeedMedium:
+		return "Medium"
+	case SpeedHigh:
+		return "High"
+	default:
+		return "Unknown"
+	}
+}
+
+func (s PlayerSpeed) RotationsPerSecond() float64 {
+	switch s {
+	case SpeedLow:
+		return 1.0
+	case SpeedMedium:
+		return 2.0
+	case SpeedHigh:
+		return 4.0
+	default:
+		return 1.0
+	}
+}
+
+// RotationTicks returns how many ticks the player's direction holds before
+// rotating, at the given ticks per second
+func (s PlayerSpeed) RotationTicks(tps int) int {
+	return max(1, int(float64(tps)/s.RotationsPerSecond()))
+}
+
+type MazeSize int
+
+const (
+	SizeSmall MazeSize = iota
+	SizeMedium
+	SizeBig
+
+	mazeSizeCount = int(SizeBig) + 1
+)
+
+// Next returns the size after this one, wrapping round to the first
+func (s MazeSize) Next() MazeSize {
+	return MazeSize((int(s) + 1) % mazeSizeCount)
+}
+
+func (s MazeSize) String() string {
+	switch s {
+	case SizeSmall:
+		return "Small"
+	case SizeMedium:
+		return "Medium"
+	case SizeBig:
+		return "Big"
+	default:
+		return "Unknown"
+	}
+}
+
+func (s MazeSize) Dimensions() (int, int) {
+	switch s {
+	case SizeSmall:
+		return 3, 3
+	case SizeMedium:
+		return 10, 10
+	case SizeBig:
+		return 20, 20
+	default:
+		return 10, 10
+	}
+}
+
+type LoopDensity int
+
+const (
+	LoopsNone LoopDensity = iota
+	LoopsLow
+	LoopsMedium
+	LoopsHigh
+
+	loopDensityCount = int(LoopsHigh) + 1
+)
+
+// Next returns the density after this one, wrapping round to the first
+func (l LoopDensity) Next() LoopDensity {
+	return LoopDensity((int(l) + 1) % loopDensityCount)
+}
+
+func (l LoopDensity) String() string {
+	switch l {
+	case LoopsNone:
+		return "None"
+	case LoopsLow:
+		return "Low"
+	case LoopsMedium:
+		return "Medium"
+	case LoopsHigh:
+		return "High"
+	default:
+		return "Unknown"
+	}
+}
+
+// Fraction returns the fraction of dead ends to braid into loops
+func (l LoopDensity) Fraction() float64 {
+	switch l {
+	case LoopsNone:
+		return 0
+	case LoopsLow:
+		return 0.25
+	case LoopsMedium:
+		return 0.5
+	case LoopsHigh:
+		return 1
+	default:
+		return 0
+	}
+}
+
+type Difficulty int
+
+const (
+	DifficultyAny Difficulty = iota
+	DifficultyEasy
+	DifficultyNormal
+	DifficultyHard
+
+	difficultyCount = int(DifficultyHard) + 1
+)
+
+// Next returns the difficulty after this one, wrapping round to the first
+func (d Difficulty) Next() Difficulty {
+	return Difficulty((int(d) + 1) % difficultyCount)
+}
+
+func (d Difficulty) String() string {
+	switch d {
+	case DifficultyAny:
+		return "Any"
+	case DifficultyEasy:
+		return "Easy"
+	case DifficultyNormal:
+		return "Normal"
+	case DifficultyHard:
+		return "Hard"
+	default:
+		return "Unknown"
+	}
+}
+
+// Band returns the range of difficulty scores mazes must fall in
+func (d Difficulty) Band() DifficultyBand {
+	switch d {
+	case DifficultyEasy:
+		return DifficultyBand{Min: 0, Max: 0.35}
+	case DifficultyNormal:
+		return DifficultyBand{Min: 0.35, Max: 0.5}
+	case DifficultyHard:
+		return DifficultyBand{Min: 0.5, Max: 1}
+	default:
+		return DifficultyBand{}
+	}
+}
+
+type MazeShape int
+
+const (
+	ShapeRectangle MazeShape = iota
+	ShapeCircle
+	ShapeHeart
+
+	mazeShapeCount = int(ShapeHeart) + 1
+)
+
+// Next returns the shape after this one, wrapping round to the first
+func (s MazeShape) Next() MazeShape {
+	return MazeShape((int(s) + 1) % mazeShapeCount)
+}
+
+func (s MazeShape) String() string {
+	switch s {
+	case ShapeRectangle:
+		return "Rectangle"
+	case ShapeCircle:
+		return "Circle"
+	case ShapeHeart:
+		return "Heart"
+	default:
+		return "Unknown"
+	}
+}
+
+// Mask returns the mask giving a maze of the given dimensions its shape, or
+// nil for a plain rectangle
+func (s MazeShape) Mask(width, height int) *Mask {
+	switch s {
+	case ShapeCircle:
+		return CircleMask(width, height)
+	case ShapeHeart:
+		return HeartMask(width, height)
+	default:
+		return nil
+	}
+}
+
+type Floors int
+
+const (
+	FloorsOne Floors = iota
+	FloorsTwo
+	FloorsThree
+
+	floorsCount = int(FloorsThree) + 1
+)
+
+// Next returns the number of floors after this one, wrapping round to the first
+func (f Floors) Next() Floors {
+	return Floors((int(f) + 1) % floorsCount)
+}
+
+func (f Floors) String() string {
+	switch f {
+	case FloorsOne:
+		return "1"
+	case FloorsTwo:
+		return "2"
+	case FloorsThree:
+		return "3"
+	default:
+		return "Unknown"
+	}
+}
+
+// Count returns the number of floors in the maze
+func (f Floors) Count() int {
+	switch f {
+	case FloorsTwo:
+		return 2
+	case FloorsThree:
+		return 3
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,35 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertCycles checks that Next goes through every value of an enum once,
+// in order, before coming back to the first
+func assertCycles[T interface {
+	~int
+	Next() T
+	String() string
+}](t *testing.T, count int) {
+	t.Helper()
+	var v T
+	for i := 0; i < count; i++ {
+		assert.Equal(t, T(i), v)
+		assert.NotEqual(t, "Unknown", v.String())
+		v = v.Next()
+	}
+	assert.Equal(t, T(0), v, "wraps round to the first")
+}
+
+func TestSettingsNext(t *testing.T) {
+	assertCycles[PlayerSpeed](t, playerSpeedCount)
+	assertCycles[MazeSize](t, mazeSizeCount)
+	assertCycles[Grid](t, gridCount)
+	assertCycles[MazeAlgorithm](t, mazeAlgorithmCount)
+	assertCycles[LoopDensity](t, loopDensityCount)
+	assertCycles[Difficulty](t, difficultyCount)
+	assertCycles[MazeShape](t, mazeShapeCount)
+	assertCycles[Floors](t, floorsCount)
+}
@@ -0,0 +1,282 @@
+package game
+
+import "container/heap"
+
+// Exit is an opening in the outer wall: moving from Position in Direction
+// leaves the maze
+type Exit struct {
+	Position  Position
+	Direction MazeDirection
+}
+
+// Exits returns every opening in the maze's outer wall, row by row
+func (m *Maze) Exits() []Exit {
+	var exits []Exit
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			for _, dir := range m.Directions(x, y) {
+				next, ok := m.Move(Position{X: x, Y: y}, dir)
+				if ok && !m.IsValidPosition(next.X, next.Y) {
+					exits = append(exits, Exit{Position: Position{X: x, Y: y}, Direction: dir})
+				}
+			}
+		}
+	}
+	return exits
+}
+
+// Move returns where moving one cell from the position in the given
+// direction leads, and false if a wall is in the way. A move through an
+// exit succeeds and returns a position outside the maze, which is how the
+// player wins. Moves carry straight on through crossings, so they may cover
+// more than one cell
+func (m *Maze) Move(from Position, direction MazeDirection) (Position, bool) {
+	if !m.IsValidPosition(from.X, from.Y) || m.HasWall(from.X, from.Y, direction) {
+		return from, false
+	}
+	x, y := m.step(from.X, from.Y, direction)
+	return Position{X: x, Y: y}, true
+}
+
+// Path is a route through the maze. Directions[i] is the move made from
+// Positions[i], so a path that ends by leaving the maze has its last
+// direction pointing out through the exit
+type Path struct {
+	Positions  []Position
+	Directions []MazeDirection
+}
+
+// Len returns the number of moves along the path
+func (p Path) Len() int {
+	return len(p.Directions)
+}
+
+// noDirection marks cells that have not been reached during a search
+const noDirection = -1
+
+// cellIndex flattens a position into an index for per-cell slices
+func (m *Maze) cellIndex(p Position) int {
+	return p.Y*m.Width + p.X
+}
+
+// DistanceMap returns the number of moves needed to get from the given
+// position to every cell, indexed as [y][x]. Unreachable cells are -1, and
+// so are crossings, which are passed through rather than reached
+func (m *Maze) DistanceMap(from Position) [][]int {
+	distances := make([][]int, m.Height)
+	for y := range distances {
+		distances[y] = make([]int, m.Width)
+		for x := range distances[y] {
+			distances[y][x] = -1
+		}
+	}
+	if !m.IsValidPosition(from.X, from.Y) {
+		return distances
+	}
+
+	distances[from.Y][from.X] = 0
+	queue := []Position{from}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		for _, dir := range m.Directions(cell.X, cell.Y) {
+			next, ok := m.Move(cell, dir)
+			if !ok || !m.IsValidPosition(next.X, next.Y) || distances[next.Y][next.X] >= 0 {
+				continue
+			}
+			distances[next.Y][next.X] = distances[cell.Y][cell.X] + 1
+			queue = append(queue, next)
+		}
+	}
+	return distances
+}
+
+// ShortestPath finds a shortest route from the given position out of the
+// maze, or to its goal, using breadth-first search. It returns false if
+// neither is reachable
+func (m *Maze) ShortestPath(from Position) (Path, bool) {
+	if !m.IsValidPosition(from.X, from.Y) {
+		return Path{}, false
+	}
+	if m.IsGoal(from) {
+		return Path{}, true
+	}
+
+	// cameFrom holds the direction each reached cell was entered by
+	cameFrom := make([]int8, m.Width*m.Height)
+	for i := range cameFrom {
+		cameFrom[i] = noDirection
+	}
+	start := m.cellIndex(from)
+	cameFrom[start] = int8(North) // Any direction, it is never followed
+
+	queue := []Position{from}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		for _, dir := range m.Directions(cell.X, cell.Y) {
+			next, ok := m.Move(cell, dir)
+			if !ok {
+				continue
+			}
+			if !m.IsValidPosition(next.X, next.Y) || m.IsGoal(next) {
+				return m.tracePath(cameFrom, from, cell, dir), true
+			}
+			if i := m.cellIndex(next); cameFrom[i] == noDirection {
+				cameFrom[i] = int8(dir)
+				queue = append(queue, next)
+			}
+		}
+	}
+	return Path{}, false
+}
+
+// ShortestPathAStar is like ShortestPath, but uses A* search guided by the
+// distance to the nearest exit or the goal. In large mazes it explores
+// fewer cells than ShortestPath when the exit is close, at the cost of a
+// priority queue
+func (m *Maze) ShortestPathAStar(from Position) (Path, bool) {
+	exits := m.Exits()
+	if (len(exits) == 0 && m.Goal == nil) || !m.IsValidPosition(from.X, from.Y) {
+		return Path{}, false
+	}
+	if m.IsGoal(from) {
+		return Path{}, true
+	}
+
+	// estimate never overestimates: it is the topology's lower bound on
+	// the distance to the nearest exit cell, plus the move out through it,
+	// or to the goal. Crossings are never next to each other, so a move
+	// covers at most two steps and half the distance is still a bound
+	topology := m.Geometry()
+	steps := func(p Position) int {
+		best := -1
+		for _, exit := range exits {
+			d := topology.Distance(p, exit.Position) + 1
+			if best < 0 || d < best {
+				best = d
+			}
+		}
+		if m.Goal != nil {
+			if d := topology.Distance(p, *m.Goal); best < 0 || d < best {
+				best = d
+			}
+		}
+		return best
+	}
+	estimate := func(p Position) int {
+		best := steps(p)
+		if m.crossings != nil {
+			return (best + 1) / 2
+		}
+		return best
+	}
+
+	cost := make([]int, m.Width*m.Height)
+	cameFrom := make([]int8, m.Width*m.Height)
+	for i := range cameFrom {
+		cameFrom[i] = noDirection
+		cost[i] = -1
+	}
+	start := m.cellIndex(from)
+	cost[start] = 0
+	cameFrom[start] = int8(North) // Any direction, it is never followed
+
+	// The outside of the maze is a node of its own, reached through any
+	// exit, and so is the goal
+	outside := len(cost)
+	bestOutside := -1
+	var exitCell Position
+	var exitDir MazeDirection
+
+	open := &searchQueue{{cell: start, cost: 0, priority: estimate(from)}}
+	for open.Len() > 0 {
+		item := heap.Pop(open).(searchItem)
+		if item.cell == outside {
+			return m.tracePath(cameFrom, from, exitCell, exitDir), true
+		}
+		if item.cost > cost[item.cell] {
+			continue // Stale entry, the cell was reached more cheaply since
+		}
+
+		cell := Position{X: item.cell % m.Width, Y: item.cell / m.Width}
+		for _, dir := range m.Directions(cell.X, cell.Y) {
+			next, ok := m.Move(cell, dir)
+			if !ok {
+				continue
+			}
+			nextCost := item.cost + 1
+			if !m.IsValidPosition(next.X, next.Y) || m.IsGoal(next) {
+				if bestOutside < 0 || nextCost < bestOutside {
+					bestOutside = nextCost
+					exitCell, exitDir = cell, dir
+					heap.Push(open, searchItem{cell: outside, cost: nextCost, priority: nextCost})
+				}
+				continue
+			}
+			i := m.cellIndex(next)
+			if cost[i] >= 0 && cost[i] <= nextCost {
+				continue
+			}
+			cost[i] = nextCost
+			cameFrom[i] = int8(dir)
+			heap.Push(open, searchItem{cell: i, cost: nextCost, priority: nextCost + estimate(next)})
+		}
+	}
+	return Path{}, false
+}
+
+// tracePath follows cameFrom back from the last cell to the start, and
+// returns the path from the start through the last cell and one more move
+// in the final direction
+func (m *Maze) tracePath(cameFrom []int8, from, last Position, final MazeDirection) Path {
+	positions := []Position{last}
+	directions := []MazeDirection{final}
+	for cell := last; cell != from; {
+		dir := MazeDirection(cameFrom[m.cellIndex(cell)])
+		x, y := m.step(cell.X, cell.Y, dir.Opposite())
+		cell = Position{X: x, Y: y}
+		positions = append(positions, cell)
+		directions = append(directions, dir)
+	}
+
+	for i, j := 0, len(positions)-1; i < j; i, j = i+1, j-1 {
+		positions[i], positions[j] = positions[j], positions[i]
+		directions[i], directions[j] = directions[j], directions[i]
+	}
+	return Path{Positions: positions, Directions: directions}
+}
+
+// searchItem is a cell waiting in the A* open set
+type searchItem struct {
+	cell     int
+	cost     int
+	priority int
+}
+
+// searchQueue is a min-heap of search items, ordered by priority and then
+// by preferring cells further along
+type searchQueue []searchItem
+
+func (q searchQueue) Len() int { return len(q) }
+func (q searchQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority < q[j].priority
+	}
+	return q[i].cost > q[j].cost
+}
+func (q searchQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *searchQueue) Push(x any)   { *q = append(*q, x.(searchItem)) }
+func (q *searchQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,122 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// solverMaze has its exit on the east side of (2, 1)
+const solverMaze = `+--+--+--+
+|        |
++  +--+  +
+|  |  |   
++--+--+--+`
+
+func TestExits(t *testing.T) {
+	maze, err := ParseMaze(solverMaze)
+	require.NoError(t, err)
+	assert.Equal(t, []Exit{{Position: Position{2, 1}, Direction: East}}, maze.Exits())
+	assert.Empty(t, NewMaze(3, 3).Exits())
+}
+
+func TestMove(t *testing.T) {
+	maze, err := ParseMaze(solverMaze)
+	require.NoError(t, err)
+
+	next, ok := maze.Move(Position{0, 0}, East)
+	assert.True(t, ok)
+	assert.Equal(t, Position{1, 0}, next)
+
+	_, ok = maze.Move(Position{0, 0}, North)
+	assert.False(t, ok, "the outer wall blocks the way")
+
+	next, ok = maze.Move(Position{2, 1}, East)
+	assert.True(t, ok, "moving through the exit succeeds")
+	assert.False(t, maze.IsValidPosition(next.X, next.Y), "and leaves the maze")
+}
+
+func TestDistanceMap(t *testing.T) {
+	maze, err := ParseMaze(solverMaze)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]int{
+		{0, 1, 2},
+		{1, -1, 3},
+	}, maze.DistanceMap(Position{0, 0}))
+}
+
+func TestShortestPath(t *testing.T) {
+	maze, err := ParseMaze(solverMaze)
+	require.NoError(t, err)
+
+	want := Path{
+		Positions:  []Position{{0, 1}, {0, 0}, {1, 0}, {2, 0}, {2, 1}},
+		Directions: []MazeDirection{North, East, East, South, East},
+	}
+
+	path, ok := maze.ShortestPath(Position{0, 1})
+	require.True(t, ok)
+	assert.Equal(t, want, path)
+	assert.Equal(t, 5, path.Len())
+
+	path, ok = maze.ShortestPathAStar(Position{0, 1})
+	require.True(t, ok)
+	assert.Equal(t, want, path)
+
+	_, ok = maze.ShortestPath(Position{1, 1})
+	assert.False(t, ok, "a walled in cell has no way out")
+	_, ok = maze.ShortestPathAStar(Position{1, 1})
+	assert.False(t, ok, "a walled in cell has no way out")
+}
+
+func TestShortestPathAStarMatchesBFS(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		maze, start := MazeConfig{Width: 30, Height: 20, Seed: seed, Braid: 0.5}.Generate()
+
+		bfs, ok := maze.ShortestPath(start)
+		require.True(t, ok)
+		astar, ok := maze.ShortestPathAStar(start)
+		require.True(t, ok)
+		assert.Equal(t, bfs.Len(), astar.Len(), "seed %d", seed)
+
+		// Following the directions must lead out of the maze
+		p := start
+		for i, dir := range astar.Directions {
+			assert.Equal(t, astar.Positions[i], p)
+			next, ok := maze.Move(p, dir)
+			require.True(t, ok)
+			p = next
+		}
+		assert.False(t, maze.IsValidPosition(p.X, p.Y))
+	}
+}
+
+func TestShortestPathAStarMatchesBFSOnWeaves(t *testing.T) {
+	// Moves through crossings cover two cells, which must not lead A* astray
+	for seed := int64(0); seed < 300; seed++ {
+		config := MazeConfig{Width: 15, Height: 15, Seed: seed, Braid: 0.5, Generator: WeaveGenerator{Density: 1}}
+		maze, start := config.Generate()
+
+		bfs, ok := maze.ShortestPath(start)
+		require.True(t, ok)
+		astar, ok := maze.ShortestPathAStar(start)
+		require.True(t, ok)
+		assert.Equal(t, bfs.Len(), astar.Len(), "seed %d", seed)
+	}
+}
+
+func BenchmarkShortestPath(b *testing.B) {
+	maze, start := MazeConfig{Width: 500, Height: 500, Seed: 1, Braid: 0.5}.Generate()
+	b.Run("BFS", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			maze.ShortestPath(start)
+		}
+	})
+	b.Run("AStar", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			maze.ShortestPathAStar(start)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Store keeps small documents, such as the player's progress, between runs
+// of the game
+type Store interface {
+	// Load returns the document saved under the key, or ErrNotStored if
+	// there is none
+	Load(key string) ([]byte, error)
+	// Save replaces the document saved under the key
+	Save(key string, data []byte) error
+}
+
+// ErrNotStored is returned by Store.Load for keys nothing was saved under
+var ErrNotStored = errors.New("nothing stored under the key")
+
+// DirStore is a Store keeping each document in a JSON file of the
+// directory, named after its key. The directory is created on first save
+type DirStore string
+
+func (d DirStore) path(key string) string {
+	return filepath.Join(string(d), key+".json")
+}
+
+func (d DirStore) Load(key string) ([]byte, error) {
+	data, err := os.ReadFile(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotStored
+	}
+	return data, err
+}
+
+func (d DirStore) Save(key string, data []byte) error {
+	if err := os.MkdirAll(string(d), 0o755); err != nil {
+		return err
+	}
+	// Write next to the document and rename, so that a crash never leaves
+	// it half written
+	tmp, err := os.CreateTemp(string(d), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), d.path(key))
+}
+
+// MemoryStore is a Store that forgets everything when the game exits, for
+// platforms where nothing can be saved
+type MemoryStore map[string][]byte
+
+func (m MemoryStore) Load(key string) ([]byte, error) {
+	data, ok := m[key]
+	if !ok {
+		return nil, ErrNotStored
+	}
+	return data, nil
+}
+
+func (m MemoryStore) Save(key string, data []byte) error {
+	m[key] = append([]byte(nil), data...)
+	return nil
+}
@@ -0,0 +1,52 @@
+//go:build js
+
+package game
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// localStorageKeyPrefix keeps the game's documents apart from whatever
+// else the page keeps in local storage
+const localStorageKeyPrefix = "trijam-304/"
+
+// localStorage is a Store on the browser's local storage
+type localStorage struct {
+	storage js.Value
+}
+
+func (s localStorage) Load(key string) ([]byte, error) {
+	value := s.storage.Call("getItem", localStorageKeyPrefix+key)
+	if value.IsNull() {
+		return nil, ErrNotStored
+	}
+	return []byte(value.String()), nil
+}
+
+func (s localStorage) Save(key string, data []byte) (err error) {
+	// Storing throws when the quota is exceeded or storage is disabled
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("saving %s: %v", key, r)
+		}
+	}()
+	s.storage.Call("setItem", localStorageKeyPrefix+key, string(data))
+	return nil
+}
+
+// DefaultStore returns the store the game saves to on this platform: the
+// browser's local storage, or memory if the page cannot use it
+func DefaultStore() (store Store) {
+	// Even looking at local storage throws on pages that may not use it
+	defer func() {
+		if recover() != nil {
+			store = MemoryStore{}
+		}
+	}()
+	storage := js.Global().Get("localStorage")
+	if storage.IsUndefined() || storage.IsNull() {
+		return MemoryStore{}
+	}
+	return localStorage{storage: storage}
+}
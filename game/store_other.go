@@ -0,0 +1,18 @@
+//go:build !js
+
+package game
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultStore returns the store the game saves to on this platform: a
+// directory in the user's configuration directory
+func DefaultStore() Store {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return MemoryStore{}
+	}
+	return DirStore(filepath.Join(dir, "trijam-304"))
+}
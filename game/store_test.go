@@ -0,0 +1,41 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]Store{
+		"dir":    DirStore(filepath.Join(t.TempDir(), "saves")),
+		"memory": MemoryStore{},
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			_, err := store.Load("progress")
+			assert.ErrorIs(t, err, ErrNotStored)
+
+			data := []byte(`{"cleared": 1}`)
+			require.NoError(t, store.Save("progress", data))
+			data[0] = 'x'
+			loaded, err := store.Load("progress")
+			require.NoError(t, err)
+			assert.Equal(t, `{"cleared": 1}`, string(loaded))
+
+			require.NoError(t, store.Save("progress", []byte(`{}`)))
+			loaded, err = store.Load("progress")
+			require.NoError(t, err)
+			assert.Equal(t, `{}`, string(loaded))
+		})
+	}
+
+	dir := stores["dir"].(DirStore)
+	entries, err := os.ReadDir(string(dir))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no temporary files are left behind")
+	assert.Equal(t, "progress.json", entries[0].Name())
+}
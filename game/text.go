@@ -0,0 +1,215 @@
+package game
+
+import "strings"
+
+// Markers MarkedString writes in the two characters inside a cell, and
+// ParseMaze reads. A cell may hold both the start and the exit marker
+const (
+	// markerStart marks where the player starts
+	markerStart = 'S'
+	// markerExit marks the cell the player leaves the maze from. When that
+	// cell has no opening in the outer wall it is the goal instead
+	markerExit = 'E'
+	// markerMasked fills cells that are left out of the maze
+	markerMasked = '#'
+	// markerCrossingEastWest and markerCrossingNorthSouth fill crossings of
+	// a weave maze, drawing the passage that goes over the bridge
+	markerCrossingEastWest   = '='
+	markerCrossingNorthSouth = 'H'
+)
+
+// cellMarkers returns the two characters MarkedString writes inside the
+// cell at the given position
+func (m *Maze) cellMarkers(x, y int) string {
+	if !m.IsValidPosition(x, y) {
+		return string([]byte{markerMasked, markerMasked})
+	}
+	switch m.Crossing(x, y) {
+	case CrossingEastWest:
+		return string([]byte{markerCrossingEastWest, markerCrossingEastWest})
+	case CrossingNorthSouth:
+		return string([]byte{markerCrossingNorthSouth, markerCrossingNorthSouth})
+	}
+
+	markers := []byte("  ")
+	p := Position{X: x, Y: y}
+	if m.Start == p {
+		markers[0] = markerStart
+	}
+	if (m.Exit != nil && m.Exit.Position == p) || m.IsGoal(p) {
+		if markers[0] == ' ' {
+			markers[0] = markerExit
+		} else {
+			markers[1] = markerExit
+		}
+	}
+	return string(markers)
+}
+
+// cellMarkerSet holds the markers ParseMaze found
+type cellMarkerSet struct {
+	start, exit *Position
+	masked      []Position
+	crossings   map[Position]Crossing
+}
+
+// parseCellMarkers reads the markers inside the cells of a maze drawn as
+// MarkedString draws it
+func (t mazeText) parseCellMarkers(width, height int) (cellMarkerSet, error) {
+	set := cellMarkerSet{crossings: map[Position]Crossing{}}
+	for y := 0; y < height; y++ {
+		line := t.lines[y*2+1]
+		for x := 0; x < width; x++ {
+			p := Position{X: x, Y: y}
+			for i := 1; i <= 2; i++ {
+				c := line[x*3+i]
+				var duplicate bool
+				switch c {
+				case ' ':
+				case markerStart:
+					duplicate = set.start != nil
+					set.start = &p
+				case markerExit:
+					duplicate = set.exit != nil
+					set.exit = &p
+				case markerMasked:
+					if len(set.masked) == 0 || set.masked[len(set.masked)-1] != p {
+						set.masked = append(set.masked, p)
+					}
+				case markerCrossingEastWest:
+					set.crossings[p] = CrossingEastWest
+				case markerCrossingNorthSouth:
+					set.crossings[p] = CrossingNorthSouth
+				default:
+					return set, t.errorf(y*2+1, x*3+i, "invalid cell marker %q", c)
+				}
+				if duplicate {
+					return set, t.errorf(y*2+1, x*3+i, "duplicate %q marker", c)
+				}
+			}
+		}
+	}
+	return set, nil
+}
+
+// applyMask leaves the cells marked as masked out of the maze
+func (s cellMarkerSet) applyMask(m *Maze) {
+	if len(s.masked) == 0 {
+		return
+	}
+	m.Mask = NewMask(m.Width, m.Height)
+	for _, p := range s.masked {
+		m.Mask.Set(p.X, p.Y, false)
+	}
+}
+
+// apply sets the start, exit or goal and crossings of a maze parsed from
+// the text
+func (s cellMarkerSet) apply(m *Maze, t mazeText) error {
+	for p, crossing := range s.crossings {
+		m.SetCrossing(p.X, p.Y, crossing)
+	}
+	if s.start != nil {
+		if !m.IsValidPosition(s.start.X, s.start.Y) || m.isCrossing(s.start.X, s.start.Y) {
+			return t.errorf(s.start.Y*2+1, s.start.X*3+1, "invalid start marker at (%d, %d)", s.start.X, s.start.Y)
+		}
+		m.Start = *s.start
+	}
+	if s.exit == nil {
+		return nil
+	}
+	if !m.IsValidPosition(s.exit.X, s.exit.Y) || m.isCrossing(s.exit.X, s.exit.Y) {
+		return t.errorf(s.exit.Y*2+1, s.exit.X*3+1, "invalid exit marker at (%d, %d)", s.exit.X, s.exit.Y)
+	}
+
+	// The exit is the opening in the outer wall next to the marker, and
+	// without one the marker is a goal
+	var exits []Exit
+	for _, exit := range m.Exits() {
+		if exit.Position == *s.exit {
+			exits = append(exits, exit)
+		}
+	}
+	switch len(exits) {
+	case 0:
+		m.Exit = nil
+		m.Goal = s.exit
+	case 1:
+		m.Exit = &exits[0]
+	default:
+		return t.errorf(s.exit.Y*2+1, s.exit.X*3+1, "exit marker at (%d, %d) has %d openings", s.exit.X, s.exit.Y, len(exits))
+	}
+	return nil
+}
+
+// boxCorners maps the walls meeting at a corner, as a bit mask of up,
+// right, down and left, to the box drawing character joining them
+var boxCorners = [16]string{
+	" ", "╵", "╶", "└", "╷", "│", "┌", "├",
+	"╴", "┘", "─", "┴", "┐", "┤", "┬", "┼",
+}
+
+// BoxString returns a drawing of the maze made of Unicode box drawing
+// characters, laid out like MarkedString and with the same markers. It is
+// meant for display, ParseMaze does not read it. Cells left out of the
+// maze have no walls between them. The drawing only describes square grids
+func (m *Maze) BoxString() string {
+	// hasWall reports whether there is a wall on the given side of the
+	// cell, as seen from either the cell or its neighbor
+	hasWall := func(x, y int, direction MazeDirection) bool {
+		nx, ny := m.Neighbor(x, y, direction)
+		return (m.IsValidPosition(x, y) && m.HasWall(x, y, direction)) ||
+			(m.IsValidPosition(nx, ny) && m.HasWall(nx, ny, direction.Opposite()))
+	}
+	// The walls to the right and below each corner
+	right := func(x, y int) bool { return x < m.Width && hasWall(x, y, North) }
+	down := func(x, y int) bool { return y < m.Height && hasWall(x, y, West) }
+
+	var result strings.Builder
+	for y := 0; y <= m.Height; y++ {
+		for x := 0; x <= m.Width; x++ {
+			corner := 0
+			if y > 0 && down(x, y-1) {
+				corner |= 1
+			}
+			if right(x, y) {
+				corner |= 2
+			}
+			if down(x, y) {
+				corner |= 4
+			}
+			if x > 0 && right(x-1, y) {
+				corner |= 8
+			}
+			result.WriteString(boxCorners[corner])
+			if x < m.Width {
+				if right(x, y) {
+					result.WriteString("──")
+				} else {
+					result.WriteString("  ")
+				}
+			}
+		}
+		result.WriteString("\n")
+		if y == m.Height {
+			break
+		}
+
+		for x := 0; x <= m.Width; x++ {
+			if down(x, y) {
+				result.WriteString("│")
+			} else {
+				result.WriteString(" ")
+			}
+			if x < m.Width {
+				if m.IsValidPosition(x, y) {
+					result.WriteString(m.cellMarkers(x, y))
+				} else {
+					result.WriteString("  ")
+				}
+			}
+		}
+		result.WriteString("\n")
+	}
+	return result.String()
+}
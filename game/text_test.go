@@ -0,0 +1,107 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkedStringRoundTrip(t *testing.T) {
+	mask, err := ParseMask(ringMask)
+	require.NoError(t, err)
+
+	configs := map[string]MazeConfig{
+		"plain":  {Width: 7, Height: 5, Seed: 1},
+		"weave":  {Width: 7, Height: 5, Seed: 2, Generator: WeaveGenerator{Density: 1}},
+		"masked": {Mask: mask, Seed: 3},
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			maze, _ := config.Generate()
+			maze.Seed, maze.Generator = 0, ""
+
+			parsed, err := ParseMaze(maze.MarkedString())
+			require.NoError(t, err)
+			assertSameMaze(t, maze, parsed)
+			assert.Equal(t, maze.MarkedString(), parsed.MarkedString())
+		})
+	}
+}
+
+func TestParseMazeMarkers(t *testing.T) {
+	t.Run("exit", func(t *testing.T) {
+		maze, err := ParseMaze("" +
+			"+--+--+--+\n" +
+			" E        \n" +
+			"+  +--+  +\n" +
+			"|  |S |  |\n" +
+			"+--+--+--+")
+		require.NoError(t, err)
+		assert.Equal(t, Position{1, 1}, maze.Start)
+		assert.Equal(t, &Exit{Position: Position{0, 0}, Direction: West}, maze.Exit, "the marker picks one of the openings")
+		assert.Nil(t, maze.Goal)
+	})
+
+	t.Run("goal", func(t *testing.T) {
+		maze, err := ParseMaze(`
++--+--+
+|S   E|
++--+--+`)
+		require.NoError(t, err)
+		assert.Nil(t, maze.Exit)
+		assert.Equal(t, &Position{1, 0}, maze.Goal)
+		path, ok := maze.ShortestPath(maze.Start)
+		require.True(t, ok)
+		assert.Equal(t, []MazeDirection{East}, path.Directions)
+	})
+
+	t.Run("start and exit in one cell", func(t *testing.T) {
+		maze := NewMaze(1, 1)
+		maze.RemoveWall(0, 0, South)
+		maze.Exit = &Exit{Position: Position{0, 0}, Direction: South}
+		assert.Equal(t, "+--+\n|SE|\n+  +\n", maze.MarkedString())
+		parsed, err := ParseMaze(maze.MarkedString())
+		require.NoError(t, err)
+		assertSameMaze(t, maze, parsed)
+	})
+
+	invalid := map[string]string{
+		"unknown marker":         "+--+\n|x |\n+--+",
+		"two starts":             "+--+--+\n|S |S |\n+--+--+",
+		"two exits":              "+--+--+\n|E |E  \n+--+--+",
+		"masked start":           "+--+--+\n|S#|  |\n+--+--+",
+		"exit with two openings": "+  +\n|E  \n+--+",
+	}
+	for name, input := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseMaze(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBoxString(t *testing.T) {
+	maze, err := ParseMaze("" +
+		"+--+--+--+\n" +
+		"|S       |\n" +
+		"+  +--+  +\n" +
+		"|  |E |   \n" +
+		"+--+--+--+")
+	require.NoError(t, err)
+	assert.Equal(t, ""+
+		"┌────────┐\n"+
+		"│S       │\n"+
+		"│  ┌──┐  ╵\n"+
+		"│  │E │   \n"+
+		"└──┴──┴──╴\n", maze.BoxString())
+
+	maze.Mask = NewMask(3, 2)
+	maze.Mask.Set(0, 1, false)
+	assert.Equal(t, ""+
+		"┌────────┐\n"+
+		"│S       │\n"+
+		"╵  ┌──┐  ╵\n"+
+		"   │E │   \n"+
+		"   └──┴──╴\n", maze.BoxString(), "no walls around masked out cells")
+}
@@ -0,0 +1,424 @@
+package game
+
+import "math"
+
+// Topology describes how the cells of a maze fit together: which walls each
+// cell has, which cell lies on the other side of each wall, and where the
+// cells and walls are when the maze is drawn
+type Topology interface {
+	// Name returns a short human readable name for the topology
+	Name() string
+	// Directions returns the directions in which the cell at the given
+	// position has walls, clockwise. The slice must not be modified
+	Directions(x, y int) []MazeDirection
+	// Neighbor returns the position of the cell on the other side of the
+	// wall in the given direction, which may be outside the grid. Moving
+	// back in the opposite direction always returns to the original cell
+	Neighbor(x, y int, direction MazeDirection) (int, int)
+	// Distance returns a lower bound on the number of steps from cell to
+	// neighboring cell between two cells, used to guide searches. It is
+	// also one on the number of moves, except that a move through a
+	// crossing takes two steps
+	Distance(a, b Position) int
+	// Size returns how large a maze of the given dimensions is when drawn,
+	// in cell units
+	Size(width, height int) (float64, float64)
+	// Center returns where the middle of the cell is when drawn, in cell
+	// units. It works for positions outside the grid too
+	Center(x, y int) Point
+	// Wall returns the points of the line drawn for the cell's wall in the
+	// given direction, in cell units
+	Wall(x, y int, direction MazeDirection) []Point
+}
+
+// Point is a location in a drawing of a maze, in cell units
+type Point struct {
+	X, Y float64
+}
+
+// Grid selects one of the built-in topologies
+type Grid int
+
+const (
+	GridSquare Grid = iota
+	GridHex
+	GridTriangle
+	GridPolar
+	GridTorus
+
+	gridCount = int(GridTorus) + 1
+)
+
+// gridByName returns the built-in grid whose topology has the given name
+func gridByName(name string) (Grid, bool) {
+	for g := 0; g < gridCount; g++ {
+		if Grid(g).String() == name {
+			return Grid(g), true
+		}
+	}
+	return GridSquare, false
+}
+
+// Next returns the grid after this one, wrapping round to the first
+func (g Grid) Next() Grid {
+	return Grid((int(g) + 1) % gridCount)
+}
+
+func (g Grid) String() string {
+	switch g {
+	case GridSquare:
+		return "Square"
+	case GridHex:
+		return "Hex"
+	case GridTriangle:
+		return "Triangle"
+	case GridPolar:
+		return "Polar"
+	case GridTorus:
+		return "Torus"
+	default:
+		return "Unknown"
+	}
+}
+
+// Topology returns the topology for a maze of the given dimensions
+func (g Grid) Topology(width, height int) Topology {
+	switch g {
+	case GridHex:
+		return HexTopology{}
+	case GridTriangle:
+		return TriangleTopology{}
+	case GridPolar:
+		return PolarTopology{Sectors: width, Rings: height}
+	case GridTorus:
+		return TorusTopology{Width: width, Height: height}
+	default:
+		return SquareTopology{}
+	}
+}
+
+var squareDirections = []MazeDirection{North, East, South, West}
+
+// SquareTopology is the classic grid of square cells with four walls each
+type SquareTopology struct{}
+
+func (SquareTopology) Name() string { return "Square" }
+
+func (SquareTopology) Directions(x, y int) []MazeDirection {
+	return squareDirections
+}
+
+func (SquareTopology) Neighbor(x, y int, direction MazeDirection) (int, int) {
+	dx, dy := direction.Offset()
+	return x + dx, y + dy
+}
+
+func (SquareTopology) Distance(a, b Position) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
+
+func (SquareTopology) Size(width, height int) (float64, float64) {
+	return float64(width), float64(height)
+}
+
+func (SquareTopology) Center(x, y int) Point {
+	return Point{X: float64(x) + 0.5, Y: float64(y) + 0.5}
+}
+
+func (SquareTopology) Wall(x, y int, direction MazeDirection) []Point {
+	left, top := float64(x), float64(y)
+	right, bottom := left+1, top+1
+	switch direction {
+	case North:
+		return []Point{{left, top}, {right, top}}
+	case East:
+		return []Point{{right, top}, {right, bottom}}
+	case South:
+		return []Point{{right, bottom}, {left, bottom}}
+	case West:
+		return []Point{{left, bottom}, {left, top}}
+	default:
+		return nil
+	}
+}
+
+var hexDirections = []MazeDirection{North, NorthEast, SouthEast, South, SouthWest, NorthWest}
+
+// hexHeight is the height of a hexagon one cell unit wide
+var hexHeight = math.Sqrt(3) / 2
+
+// hexCorners are the corners of a hexagon around its center, clockwise
+// from the top left, so that the wall in hexDirections[i] runs from corner
+// i to corner i+1
+var hexCorners = [6]Point{
+	{-0.25, -hexHeight / 2},
+	{0.25, -hexHeight / 2},
+	{0.5, 0},
+	{0.25, hexHeight / 2},
+	{-0.25, hexHeight / 2},
+	{-0.5, 0},
+}
+
+// HexTopology is a grid of flat-topped hexagons with six walls each. The
+// cells form columns, and every odd column is shifted half a cell down
+type HexTopology struct{}
+
+func (HexTopology) Name() string { return "Hex" }
+
+func (HexTopology) Directions(x, y int) []MazeDirection {
+	return hexDirections
+}
+
+func (HexTopology) Neighbor(x, y int, direction MazeDirection) (int, int) {
+	// Odd columns sit lower, so the diagonal neighbors of an even column
+	// are one row further up than those of an odd column
+	shift := 0
+	if x%2 == 0 {
+		shift = -1
+	}
+	switch direction {
+	case North:
+		return x, y - 1
+	case South:
+		return x, y + 1
+	case NorthEast:
+		return x + 1, y + shift
+	case SouthEast:
+		return x + 1, y + shift + 1
+	case SouthWest:
+		return x - 1, y + shift + 1
+	case NorthWest:
+		return x - 1, y + shift
+	default:
+		return x, y
+	}
+}
+
+func (HexTopology) Distance(a, b Position) int {
+	// Convert to axial coordinates, where the distance has a closed form
+	aq, ar := a.X, a.Y-(a.X-a.X&1)/2
+	bq, br := b.X, b.Y-(b.X-b.X&1)/2
+	dq, dr := aq-bq, ar-br
+	return (abs(dq) + abs(dr) + abs(dq+dr)) / 2
+}
+
+func (HexTopology) Size(width, height int) (float64, float64) {
+	h := float64(height) * hexHeight
+	if width > 1 {
+		h += hexHeight / 2
+	}
+	return 0.75*float64(width) + 0.25, h
+}
+
+func (HexTopology) Center(x, y int) Point {
+	cy := (float64(y) + 0.5) * hexHeight
+	if x%2 != 0 {
+		cy += hexHeight / 2
+	}
+	return Point{X: 0.75*float64(x) + 0.5, Y: cy}
+}
+
+func (t HexTopology) Wall(x, y int, direction MazeDirection) []Point {
+	for i, d := range hexDirections {
+		if d == direction {
+			c := t.Center(x, y)
+			from, to := hexCorners[i], hexCorners[(i+1)%len(hexCorners)]
+			return []Point{{c.X + from.X, c.Y + from.Y}, {c.X + to.X, c.Y + to.Y}}
+		}
+	}
+	return nil
+}
+
+var (
+	upTriangleDirections   = []MazeDirection{East, South, West}
+	downTriangleDirections = []MazeDirection{North, East, West}
+)
+
+// TriangleTopology is a grid of triangles with three walls each. Triangles
+// alternate between pointing up, when x+y is even, and pointing down, so
+// up triangles have a south wall and down triangles a north wall
+type TriangleTopology struct{}
+
+func (TriangleTopology) Name() string { return "Triangle" }
+
+// pointsUp reports whether the triangle at the given position points up
+func (TriangleTopology) pointsUp(x, y int) bool {
+	return (x+y)%2 == 0
+}
+
+func (t TriangleTopology) Directions(x, y int) []MazeDirection {
+	if t.pointsUp(x, y) {
+		return upTriangleDirections
+	}
+	return downTriangleDirections
+}
+
+func (TriangleTopology) Neighbor(x, y int, direction MazeDirection) (int, int) {
+	dx, dy := direction.Offset()
+	return x + dx, y + dy
+}
+
+func (TriangleTopology) Distance(a, b Position) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
+
+func (TriangleTopology) Size(width, height int) (float64, float64) {
+	return float64(width+1) / 2, float64(height) * hexHeight
+}
+
+func (t TriangleTopology) Center(x, y int) Point {
+	c := Point{X: float64(x)/2 + 0.5, Y: float64(y) * hexHeight}
+	// The center of a triangle is a third of the way up from its base
+	if t.pointsUp(x, y) {
+		c.Y += hexHeight * 2 / 3
+	} else {
+		c.Y += hexHeight / 3
+	}
+	return c
+}
+
+func (t TriangleTopology) Wall(x, y int, direction MazeDirection) []Point {
+	left := float64(x) / 2
+	top, bottom := float64(y)*hexHeight, float64(y+1)*hexHeight
+	if t.pointsUp(x, y) {
+		apex := Point{left + 0.5, top}
+		switch direction {
+		case East:
+			return []Point{apex, {left + 1, bottom}}
+		case South:
+			return []Point{{left + 1, bottom}, {left, bottom}}
+		case West:
+			return []Point{{left, bottom}, apex}
+		}
+		return nil
+	}
+	apex := Point{left + 0.5, bottom}
+	switch direction {
+	case North:
+		return []Point{{left, top}, {left + 1, top}}
+	case East:
+		return []Point{{left + 1, top}, apex}
+	case West:
+		return []Point{apex, {left, top}}
+	}
+	return nil
+}
+
+// PolarTopology is a disc of concentric rings around a round hole in the
+// middle. Rows are rings, counted outward from the hole, and columns are
+// sectors, counted clockwise from the top. North leads inward, South
+// outward, and East and West go round the ring, wrapping past the last
+// sector. Every ring has the same number of sectors, so cells get wider
+// toward the rim
+type PolarTopology struct {
+	Sectors int
+	Rings   int
+}
+
+func (PolarTopology) Name() string { return "Polar" }
+
+func (PolarTopology) Directions(x, y int) []MazeDirection {
+	return squareDirections
+}
+
+func (t PolarTopology) Neighbor(x, y int, direction MazeDirection) (int, int) {
+	switch direction {
+	case East:
+		return (x + 1) % t.Sectors, y
+	case West:
+		return (x - 1 + t.Sectors) % t.Sectors, y
+	default:
+		dx, dy := direction.Offset()
+		return x + dx, y + dy
+	}
+}
+
+func (t PolarTopology) Distance(a, b Position) int {
+	dx := abs(a.X - b.X)
+	return min(dx, t.Sectors-dx) + abs(a.Y-b.Y)
+}
+
+// holeRadius returns the radius of the hole in the middle, chosen so that
+// cells of the innermost ring are about as wide as they are deep
+func (t PolarTopology) holeRadius() float64 {
+	return max(1, float64(t.Sectors)/(2*math.Pi))
+}
+
+func (t PolarTopology) Size(width, height int) (float64, float64) {
+	diameter := 2 * (t.holeRadius() + float64(height))
+	return diameter, diameter
+}
+
+// point returns the point at the given distance from the middle of the
+// disc, at an angle measured in sectors clockwise from the top
+func (t PolarTopology) point(radius, angle float64) Point {
+	middle := t.holeRadius() + float64(t.Rings)
+	theta := 2 * math.Pi * angle / float64(t.Sectors)
+	return Point{X: middle + radius*math.Sin(theta), Y: middle - radius*math.Cos(theta)}
+}
+
+func (t PolarTopology) Center(x, y int) Point {
+	return t.point(t.holeRadius()+float64(y)+0.5, float64(x)+0.5)
+}
+
+// arcSteps is how many straight segments approximate each sector's arc
+const arcSteps = 8
+
+func (t PolarTopology) Wall(x, y int, direction MazeDirection) []Point {
+	inner := t.holeRadius() + float64(y)
+	outer := inner + 1
+	arc := func(radius, from, to float64) []Point {
+		points := make([]Point, arcSteps+1)
+		for i := range points {
+			points[i] = t.point(radius, from+(to-from)*float64(i)/arcSteps)
+		}
+		return points
+	}
+	start, end := float64(x), float64(x+1)
+	switch direction {
+	case North:
+		return arc(inner, start, end)
+	case East:
+		return []Point{t.point(inner, end), t.point(outer, end)}
+	case South:
+		return arc(outer, end, start)
+	case West:
+		return []Point{t.point(outer, start), t.point(inner, start)}
+	default:
+		return nil
+	}
+}
+
+// TorusTopology is a grid of squares without an outer wall: leaving through
+// the east edge leads back in through the west edge, and leaving through
+// the south edge back in through the north edge. It is drawn like a square
+// grid, so walls across the edges show up on both sides
+type TorusTopology struct {
+	SquareTopology
+	Width, Height int
+}
+
+func (TorusTopology) Name() string { return "Torus" }
+
+func (t TorusTopology) Neighbor(x, y int, direction MazeDirection) (int, int) {
+	dx, dy := direction.Offset()
+	return (x + dx + t.Width) % t.Width, (y + dy + t.Height) % t.Height
+}
+
+func (t TorusTopology) Distance(a, b Position) int {
+	dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+	return min(dx, t.Width-dx) + min(dy, t.Height-dy)
+}
+
+// IsWrapped reports whether the wall of the cell in the given direction is
+// drawn in a different place from the same wall seen from the cell on the
+// other side, as happens where a torus wraps around
+func IsWrapped(topology Topology, x, y int, direction MazeDirection) bool {
+	nx, ny := topology.Neighbor(x, y, direction)
+	wall, other := topology.Wall(x, y, direction), topology.Wall(nx, ny, direction.Opposite())
+	if len(wall) == 0 || len(other) == 0 {
+		return false
+	}
+	a, b := wall[0], other[len(other)-1]
+	return math.Abs(a.X-b.X) > 1e-9 || math.Abs(a.Y-b.Y) > 1e-9
+}
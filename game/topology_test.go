@@ -0,0 +1,167 @@
+package game
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopologyNeighbors(t *testing.T) {
+	const width, height = 6, 5
+	for g := 0; g < gridCount; g++ {
+		grid := Grid(g)
+		topology := grid.Topology(width, height)
+		t.Run(grid.String(), func(t *testing.T) {
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					for _, dir := range topology.Directions(x, y) {
+						nx, ny := topology.Neighbor(x, y, dir)
+						bx, by := topology.Neighbor(nx, ny, dir.Opposite())
+						assert.Equal(t, [2]int{x, y}, [2]int{bx, by}, "(%d, %d) %s and back", x, y, dir)
+						assert.Contains(t, topology.Directions(nx, ny), dir.Opposite(), "(%d, %d) %s", x, y, dir)
+
+						// Both sides of a wall are drawn in the same place,
+						// except where a torus wraps around
+						if grid == GridTorus && IsWrapped(topology, x, y, dir) {
+							continue
+						}
+						wall := topology.Wall(x, y, dir)
+						other := topology.Wall(nx, ny, dir.Opposite())
+						require.NotEmpty(t, wall)
+						require.Len(t, other, len(wall))
+						for i := range wall {
+							assertNear(t, wall[i], other[len(other)-1-i])
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTopologyDistance(t *testing.T) {
+	const width, height = 7, 6
+	for g := 0; g < gridCount; g++ {
+		grid := Grid(g)
+		t.Run(grid.String(), func(t *testing.T) {
+			// With every interior wall gone, distances are as short as the
+			// topology allows
+			maze := NewMazeWithTopology(width, height, grid.Topology(width, height))
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					for _, dir := range maze.Directions(x, y) {
+						if maze.IsValidPosition(maze.Neighbor(x, y, dir)) {
+							maze.RemoveWall(x, y, dir)
+						}
+					}
+				}
+			}
+
+			from := Position{X: 3, Y: 2}
+			for y, row := range maze.DistanceMap(from) {
+				for x, d := range row {
+					estimate := maze.Geometry().Distance(from, Position{X: x, Y: y})
+					assert.LessOrEqual(t, estimate, d, "distance to (%d, %d) must not be overestimated", x, y)
+					if grid != GridTriangle {
+						assert.Equal(t, d, estimate, "distance to (%d, %d)", x, y)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPolarWraps(t *testing.T) {
+	maze := NewMazeWithTopology(4, 2, GridPolar.Topology(4, 2))
+	maze.RemoveWall(3, 1, East)
+	assert.False(t, maze.HasWall(0, 1, West), "the last sector connects to the first")
+
+	next, ok := maze.Move(Position{3, 1}, East)
+	require.True(t, ok)
+	assert.Equal(t, Position{0, 1}, next)
+}
+
+func TestTorusWraps(t *testing.T) {
+	maze := NewMazeWithTopology(4, 3, GridTorus.Topology(4, 3))
+	maze.RemoveWall(3, 1, East)
+	assert.False(t, maze.HasWall(0, 1, West), "the east edge connects to the west edge")
+	maze.RemoveWall(2, 0, North)
+	assert.False(t, maze.HasWall(2, 2, South), "the north edge connects to the south edge")
+	assert.Empty(t, maze.Exits(), "a torus has no outer wall")
+
+	next, ok := maze.Move(Position{3, 1}, East)
+	require.True(t, ok)
+	assert.Equal(t, Position{0, 1}, next)
+
+	topology := maze.Geometry()
+	assert.True(t, IsWrapped(topology, 3, 1, East))
+	assert.True(t, IsWrapped(topology, 2, 2, South))
+	assert.False(t, IsWrapped(topology, 1, 1, East))
+	assert.False(t, IsWrapped(SquareTopology{}, 3, 1, East))
+}
+
+func TestTorusGoal(t *testing.T) {
+	placements := []Placement{PlacementRandom, PlacementFarthest, PlacementCorner, PlacementMinDistance}
+	for _, placement := range placements {
+		t.Run(placement.String(), func(t *testing.T) {
+			for seed := int64(0); seed < 10; seed++ {
+				config := MazeConfig{
+					Width:       9,
+					Height:      7,
+					Seed:        seed,
+					Grid:        GridTorus,
+					Placement:   placement,
+					MinDistance: 10,
+					Generator:   AlgorithmWeave.Generator(),
+				}
+				maze, start := config.Generate()
+				assert.Nil(t, maze.Exit, "seed %d", seed)
+				require.NotNil(t, maze.Goal, "seed %d", seed)
+				assert.NotEqual(t, start, *maze.Goal, "seed %d", seed)
+				assert.False(t, maze.isCrossing(maze.Goal.X, maze.Goal.Y), "seed %d", seed)
+
+				path, ok := maze.ShortestPath(start)
+				require.True(t, ok, "seed %d", seed)
+				distance := maze.DistanceMap(start)[maze.Goal.Y][maze.Goal.X]
+				assert.Equal(t, distance, path.Len(), "seed %d", seed)
+				astar, ok := maze.ShortestPathAStar(start)
+				require.True(t, ok, "seed %d", seed)
+				assert.Equal(t, path.Len(), astar.Len(), "seed %d", seed)
+
+				_, ok = maze.SolveRotating(start, 3)
+				assert.True(t, ok, "seed %d", seed)
+
+				if placement == PlacementMinDistance {
+					assert.GreaterOrEqual(t, distance, 10, "seed %d", seed)
+				}
+				if placement == PlacementFarthest {
+					_, farthest := farthestCell(maze.DistanceMap(start))
+					assert.Equal(t, farthest, distance, "seed %d", seed)
+				}
+			}
+		})
+	}
+}
+
+func TestSolveRotatingHex(t *testing.T) {
+	maze := NewMazeWithTopology(1, 1, HexTopology{})
+	maze.RemoveWall(0, 0, SouthWest)
+
+	// The direction cycles North, NorthEast, SouthEast, South, SouthWest,
+	// so SouthWest comes up on tick 8
+	solution, ok := maze.SolveRotating(Position{0, 0}, 2)
+	require.True(t, ok)
+	assert.Equal(t, RotationSolution{
+		Ticks:      8,
+		Presses:    1,
+		MinPresses: 1,
+		Releases:   []Release{{Tick: 8, Position: Position{0, 0}, Direction: SouthWest}},
+	}, solution)
+}
+
+func assertNear(t *testing.T, want, got Point) {
+	t.Helper()
+	assert.True(t, math.Abs(want.X-got.X) < 1e-9 && math.Abs(want.Y-got.Y) < 1e-9, "want %v, got %v", want, got)
+}
@@ -0,0 +1,206 @@
+package game
+
+import "math/rand"
+
+// Location is a cell on one of the floors of a Tower
+type Location struct {
+	Floor int
+	Position
+}
+
+// Tower is a maze of several floors stacked on top of each other and joined
+// by stairs. All floors have the same dimensions and topology, and a
+// staircase joins the same cell on two floors next to each other. A cell
+// has stairs up when its Up wall is missing, and stairs down when its Down
+// wall is missing
+type Tower struct {
+	// Floors holds the floors from the ground up
+	Floors []*Maze
+	// Start is where the player begins
+	Start Location
+}
+
+// NewTower stacks the floors into a tower, with the player starting at the
+// start of the ground floor
+func NewTower(floors ...*Maze) *Tower {
+	return &Tower{Floors: floors, Start: Location{Position: floors[0].Start}}
+}
+
+// IsGoal reports whether the location is the goal cell of its floor
+func (t *Tower) IsGoal(l Location) bool {
+	return l.Floor >= 0 && l.Floor < len(t.Floors) && t.Floors[l.Floor].IsGoal(l.Position)
+}
+
+// IsValidLocation checks if the location is on one of the floors and inside
+// its maze
+func (t *Tower) IsValidLocation(l Location) bool {
+	return l.Floor >= 0 && l.Floor < len(t.Floors) && t.Floors[l.Floor].IsValidPosition(l.X, l.Y)
+}
+
+// Cells returns the number of cells on all floors of the tower
+func (t *Tower) Cells() int {
+	cells := 0
+	for _, floor := range t.Floors {
+		cells += floor.cellCount()
+	}
+	return cells
+}
+
+// AddStairs joins the cell at the given position on a floor to the same
+// cell on the floor above
+func (t *Tower) AddStairs(floor int, x, y int) {
+	if !t.IsValidLocation(Location{floor, Position{x, y}}) || !t.IsValidLocation(Location{floor + 1, Position{x, y}}) {
+		return
+	}
+	t.Floors[floor].RemoveWall(x, y, Up)
+	t.Floors[floor+1].RemoveWall(x, y, Down)
+}
+
+// Directions returns the directions the player can face at the location:
+// those of the cell on its floor, clockwise, then Up and Down if the cell
+// has stairs that way
+func (t *Tower) Directions(l Location) []MazeDirection {
+	floor := t.Floors[l.Floor]
+	directions := floor.Directions(l.X, l.Y)
+	if floor.HasWall(l.X, l.Y, Up) && floor.HasWall(l.X, l.Y, Down) {
+		return directions
+	}
+
+	directions = append([]MazeDirection(nil), directions...)
+	for _, dir := range [2]MazeDirection{Up, Down} {
+		if !floor.HasWall(l.X, l.Y, dir) {
+			directions = append(directions, dir)
+		}
+	}
+	return directions
+}
+
+// Move returns where moving from the location in the given direction leads,
+// and false if a wall is in the way. Up and Down take the stairs, any other
+// direction moves within the floor as Maze.Move does, so a move through an
+// exit returns a position outside the floor's maze
+func (t *Tower) Move(from Location, direction MazeDirection) (Location, bool) {
+	if !t.IsValidLocation(from) {
+		return from, false
+	}
+	floor := t.Floors[from.Floor]
+	switch direction {
+	case Up, Down:
+		if floor.HasWall(from.X, from.Y, direction) {
+			return from, false
+		}
+		next := from
+		if direction == Up {
+			next.Floor++
+		} else {
+			next.Floor--
+		}
+		return next, t.IsValidLocation(next)
+	default:
+		next, ok := floor.Move(from.Position, direction)
+		return Location{Floor: from.Floor, Position: next}, ok
+	}
+}
+
+// Stairs returns the locations of every staircase going up, floor by floor
+// and row by row
+func (t *Tower) Stairs() []Location {
+	var stairs []Location
+	for f, floor := range t.Floors[:max(0, len(t.Floors)-1)] {
+		for y := 0; y < floor.Height; y++ {
+			for x := 0; x < floor.Width; x++ {
+				if floor.IsValidPosition(x, y) && !floor.HasWall(x, y, Up) {
+					stairs = append(stairs, Location{Floor: f, Position: Position{X: x, Y: y}})
+				}
+			}
+		}
+	}
+	return stairs
+}
+
+// ShortestPath finds a shortest route from the given location out of the
+// tower, or to a goal, taking the stairs where needed. It returns the
+// locations along the way and the direction of the move made from each of
+// them, and false if there is no way out
+func (t *Tower) ShortestPath(from Location) ([]Location, []MazeDirection, bool) {
+	if !t.IsValidLocation(from) {
+		return nil, nil, false
+	}
+
+	if t.IsGoal(from) {
+		return nil, nil, true
+	}
+
+	type step struct {
+		from      Location
+		direction MazeDirection
+	}
+	cameFrom := map[Location]step{from: {}}
+	queue := []Location{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dir := range t.Directions(current) {
+			next, ok := t.Move(current, dir)
+			if !ok {
+				continue
+			}
+			if !t.IsValidLocation(next) || t.IsGoal(next) {
+				// Out through the exit or at the goal, walk back to the start
+				locations := []Location{current}
+				directions := []MazeDirection{dir}
+				for l := current; l != from; {
+					s := cameFrom[l]
+					locations = append(locations, s.from)
+					directions = append(directions, s.direction)
+					l = s.from
+				}
+				for i, j := 0, len(locations)-1; i < j; i, j = i+1, j-1 {
+					locations[i], locations[j] = locations[j], locations[i]
+					directions[i], directions[j] = directions[j], directions[i]
+				}
+				return locations, directions, true
+			}
+			if _, seen := cameFrom[next]; !seen {
+				cameFrom[next] = step{from: current, direction: dir}
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// GenerateTower creates a tower of c.Floors floors, each carved as Generate
+// would carve a single maze, with one staircase between each floor and the
+// next so that a perfect maze stays perfect. The player starts on the
+// ground floor and the only exit, or goal, is on the top floor. A tower of
+// one floor holds the same maze Generate returns for the config
+func (c MazeConfig) GenerateTower() *Tower {
+	rng := rand.New(rand.NewSource(c.Seed))
+	floors := make([]*Maze, max(1, c.Floors))
+	for i := range floors {
+		floors[i], _ = c.generate(rng)
+	}
+
+	// Only the top floor keeps its exit or goal
+	for _, floor := range floors[:len(floors)-1] {
+		if floor.Exit != nil {
+			floor.AddWall(floor.Exit.Position.X, floor.Exit.Position.Y, floor.Exit.Direction)
+			floor.Exit = nil
+		}
+		floor.Goal = nil
+	}
+
+	tower := NewTower(floors...)
+	for f := 0; f+1 < len(floors); f++ {
+		// The stairs cannot go on a crossing, nor right where the player
+		// starts
+		cell := floors[f].randomCell(rng)
+		for floors[f].isCrossing(cell.X, cell.Y) || floors[f+1].isCrossing(cell.X, cell.Y) ||
+			(f == 0 && cell == tower.Start.Position && floors[f].cellCount() > 1) {
+			cell = floors[f].randomCell(rng)
+		}
+		tower.AddStairs(f, cell.X, cell.Y)
+	}
+	return tower
+}
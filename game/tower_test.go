@@ -0,0 +1,62 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTowerStairs(t *testing.T) {
+	tower := NewTower(NewMaze(2, 2), NewMaze(2, 2))
+	tower.AddStairs(0, 1, 0)
+
+	assert.Equal(t, []MazeDirection{North, East, South, West, Up}, tower.Directions(Location{0, Position{1, 0}}))
+	assert.Equal(t, []MazeDirection{North, East, South, West, Down}, tower.Directions(Location{1, Position{1, 0}}))
+	assert.Equal(t, []MazeDirection{North, East, South, West}, tower.Directions(Location{0, Position{0, 0}}))
+	assert.Equal(t, []Location{{0, Position{1, 0}}}, tower.Stairs())
+	assert.Equal(t, 8, tower.Cells())
+
+	next, ok := tower.Move(Location{0, Position{1, 0}}, Up)
+	require.True(t, ok)
+	assert.Equal(t, Location{1, Position{1, 0}}, next)
+
+	next, ok = tower.Move(next, Down)
+	require.True(t, ok)
+	assert.Equal(t, Location{0, Position{1, 0}}, next)
+
+	_, ok = tower.Move(Location{0, Position{0, 0}}, Up)
+	assert.False(t, ok, "no stairs here")
+	_, ok = tower.Move(Location{0, Position{1, 0}}, Down)
+	assert.False(t, ok, "nothing below the ground floor")
+}
+
+func TestGenerateTower(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		config := MazeConfig{Width: 8, Height: 6, Seed: seed, Floors: 3}
+		tower := config.GenerateTower()
+		require.Len(t, tower.Floors, 3)
+
+		assert.Empty(t, tower.Floors[0].Exits(), "only the top floor has an exit")
+		assert.Empty(t, tower.Floors[1].Exits(), "only the top floor has an exit")
+		assert.Len(t, tower.Floors[2].Exits(), 1)
+		assert.Len(t, tower.Stairs(), 2, "one staircase between each pair of floors")
+
+		locations, directions, ok := tower.ShortestPath(tower.Start)
+		require.True(t, ok, "seed %d", seed)
+		assert.Equal(t, 0, locations[0].Floor)
+		assert.Equal(t, 2, locations[len(locations)-1].Floor)
+		assert.Contains(t, directions, Up)
+
+		assert.Equal(t, tower.Floors[1].String(), config.GenerateTower().Floors[1].String(), "the same config should give the same tower")
+	}
+}
+
+func TestGenerateTowerSingleFloor(t *testing.T) {
+	config := MazeConfig{Width: 8, Height: 6, Seed: 3}
+	maze, start := config.Generate()
+	tower := config.GenerateTower()
+	require.Len(t, tower.Floors, 1)
+	assert.Equal(t, maze.String(), tower.Floors[0].String())
+	assert.Equal(t, Location{Position: start}, tower.Start)
+}
@@ -0,0 +1,133 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Wall identifies one side of a cell
+type Wall struct {
+	Position  Position
+	Direction MazeDirection
+}
+
+// Validation is what Validate found out about a maze
+type Validation struct {
+	// StartInvalid is set when the start is outside the maze or on a
+	// crossing, where the player cannot stand
+	StartInvalid bool
+	// AsymmetricWalls lists the open walls whose way back does not return
+	// through the same wall, from the side they were gone through. Each wall
+	// is stored once for both cells it separates, so this does not catch a
+	// wall open on one side only: the text and binary formats write each
+	// wall once, and UnmarshalJSON rejects such walls. It only catches
+	// topologies whose neighbors do not agree
+	AsymmetricWalls []Wall
+	// Unreachable lists the cells that cannot be reached from the start,
+	// row by row
+	Unreachable []Position
+	// WaysOut is the number of openings in the outer wall, plus one for
+	// the goal cell if there is one
+	WaysOut int
+	// ExitWalled is set when the maze's exit does not lead out through an
+	// opening in the outer wall
+	ExitWalled bool
+	// Perfect is set when there is exactly one route between any two cells:
+	// every cell is reachable and there are no loops
+	Perfect bool
+	// ExitReachable is set when the way out can be reached from the
+	// position given to Validate
+	ExitReachable bool
+}
+
+// Validate checks the structure of the maze and whether it can be solved
+// from the given position. Handcrafted mazes should be checked before they
+// are played. Stairs are not followed, so the floors of a tower other than
+// the top one have no way out of their own
+func (m *Maze) Validate(from Position) Validation {
+	v := Validation{
+		StartInvalid: !m.IsValidPosition(m.Start.X, m.Start.Y) || m.isCrossing(m.Start.X, m.Start.Y),
+		WaysOut:      len(m.Exits()),
+	}
+	if m.Goal != nil {
+		v.WaysOut++
+	}
+	if m.Exit != nil {
+		next, ok := m.Move(m.Exit.Position, m.Exit.Direction)
+		v.ExitWalled = !ok || m.IsValidPosition(next.X, next.Y)
+	}
+
+	// Going through an open wall and turning round must lead back through
+	// the same open wall, which only a faulty topology can break
+	cells, passages := 0, 0
+	distances := m.DistanceMap(m.Start)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if !m.IsValidPosition(x, y) || m.isCrossing(x, y) {
+				continue
+			}
+			cells++
+			if distances[y][x] < 0 {
+				v.Unreachable = append(v.Unreachable, Position{X: x, Y: y})
+			}
+			for _, dir := range m.Directions(x, y) {
+				if m.HasWall(x, y, dir) {
+					continue
+				}
+				if nx, ny := m.Neighbor(x, y, dir); m.IsValidPosition(nx, ny) && !m.opensBack(nx, ny, dir.Opposite(), x, y) {
+					v.AsymmetricWalls = append(v.AsymmetricWalls, Wall{Position: Position{X: x, Y: y}, Direction: dir})
+				}
+				if next, ok := m.Move(Position{X: x, Y: y}, dir); ok && m.IsValidPosition(next.X, next.Y) {
+					passages++
+				}
+			}
+		}
+	}
+
+	// Every passage was counted from both ends
+	v.Perfect = len(v.AsymmetricWalls) == 0 && len(v.Unreachable) == 0 && passages/2 == cells-1
+	_, v.ExitReachable = m.ShortestPath(from)
+	return v
+}
+
+// opensBack reports whether the cell at (x, y) has an open wall in the
+// given direction that leads to (toX, toY)
+func (m *Maze) opensBack(x, y int, direction MazeDirection, toX, toY int) bool {
+	if !m.hasDirection(x, y, direction) || m.HasWall(x, y, direction) {
+		return false
+	}
+	bx, by := m.Neighbor(x, y, direction)
+	return bx == toX && by == toY
+}
+
+// Err returns the problems that make the maze unplayable, or nil if there
+// are none. Mazes that are not perfect are still playable
+func (v Validation) Err() error {
+	var errs []error
+	if v.StartInvalid {
+		errs = append(errs, errors.New("the start is not a cell of the maze"))
+	}
+	if len(v.AsymmetricWalls) > 0 {
+		w := v.AsymmetricWalls[0]
+		errs = append(errs, fmt.Errorf("%d open walls do not lead back the way they came, the first at (%d, %d) towards %v",
+			len(v.AsymmetricWalls), w.Position.X, w.Position.Y, w.Direction))
+	}
+	if len(v.Unreachable) > 0 {
+		p := v.Unreachable[0]
+		errs = append(errs, fmt.Errorf("%d cells cannot be reached from the start, the first at (%d, %d)",
+			len(v.Unreachable), p.X, p.Y))
+	}
+	switch {
+	case v.WaysOut == 0:
+		errs = append(errs, errors.New("there is no exit"))
+	case v.WaysOut > 1:
+		errs = append(errs, fmt.Errorf("there are %d exits", v.WaysOut))
+	}
+	if v.ExitWalled {
+		errs = append(errs, errors.New("the exit is walled"))
+	}
+	if v.WaysOut > 0 && !v.ExitReachable {
+		errs = append(errs, errors.New("the exit cannot be reached"))
+	}
+	return errors.Join(errs...)
+}
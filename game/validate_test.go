@@ -0,0 +1,83 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateGenerated(t *testing.T) {
+	for a := 0; a < mazeAlgorithmCount; a++ {
+		for g := 0; g < gridCount; g++ {
+			config := MazeConfig{Width: 8, Height: 6, Seed: int64(a*gridCount + g), Grid: Grid(g), Generator: MazeAlgorithm(a).Generator()}
+			maze, _ := config.Generate()
+			v := maze.Validate(maze.Start)
+			assert.NoError(t, v.Err(), "%s on %s", MazeAlgorithm(a), Grid(g))
+			assert.True(t, v.Perfect, "%s on %s", MazeAlgorithm(a), Grid(g))
+			assert.True(t, v.ExitReachable)
+		}
+	}
+
+	maze, _ := MazeConfig{Width: 8, Height: 6, Seed: 1, Braid: 1}.Generate()
+	v := maze.Validate(maze.Start)
+	assert.NoError(t, v.Err(), "loops are allowed")
+	assert.False(t, v.Perfect)
+}
+
+func TestValidate(t *testing.T) {
+	maze, err := ParseMaze(solverMaze)
+	require.NoError(t, err)
+	maze.Start = Position{0, 0}
+
+	v := maze.Validate(Position{0, 1})
+	assert.Equal(t, []Position{{1, 1}}, v.Unreachable)
+	assert.Equal(t, 1, v.WaysOut)
+	assert.False(t, v.Perfect)
+	assert.True(t, v.ExitReachable)
+	assert.ErrorContains(t, v.Err(), "1 cells cannot be reached from the start, the first at (1, 1)")
+	assert.False(t, maze.Validate(Position{1, 1}).ExitReachable)
+
+	// Opening up the enclosed cell makes the maze perfect
+	maze.RemoveWall(1, 1, North)
+	v = maze.Validate(maze.Start)
+	assert.NoError(t, v.Err())
+	assert.True(t, v.Perfect)
+
+	maze.RemoveWall(0, 1, South)
+	assert.ErrorContains(t, maze.Validate(maze.Start).Err(), "there are 2 exits")
+	maze.AddWall(0, 1, South)
+	maze.AddWall(2, 1, East)
+	assert.ErrorContains(t, maze.Validate(maze.Start).Err(), "there is no exit")
+	assert.ErrorContains(t, maze.Validate(maze.Start).Err(), "the exit is walled")
+
+	maze.Start = Position{5, 5}
+	assert.True(t, maze.Validate(maze.Start).StartInvalid)
+}
+
+func TestValidateAsymmetricWalls(t *testing.T) {
+	maze := NewMazeWithTopology(3, 1, skippingTopology{})
+	maze.RemoveWall(0, 0, East)
+	maze.Goal = &Position{2, 0}
+
+	v := maze.Validate(maze.Start)
+	assert.Equal(t, []Wall{
+		{Position: Position{0, 0}, Direction: East},
+		{Position: Position{1, 0}, Direction: West},
+	}, v.AsymmetricWalls, "both cells share the wall but lead elsewhere through it")
+	assert.False(t, v.Perfect)
+	assert.ErrorContains(t, v.Err(), "2 open walls do not lead back the way they came, the first at (0, 0) towards East")
+}
+
+// skippingTopology is a square grid whose first cell leads east past its
+// neighbor, breaking the promise that moving back returns to the cell
+type skippingTopology struct{ SquareTopology }
+
+func (skippingTopology) Name() string { return "Skipping" }
+
+func (t skippingTopology) Neighbor(x, y int, direction MazeDirection) (int, int) {
+	if x == 0 && y == 0 && direction == East {
+		return 2, 0
+	}
+	return t.SquareTopology.Neighbor(x, y, direction)
+}
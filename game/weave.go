@@ -0,0 +1,290 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Crossing describes a cell where two passages cross without meeting, one
+// over a bridge and the other through a tunnel underneath. The player never
+// stops on a crossing, moves carry straight through it
+type Crossing uint8
+
+const (
+	NoCrossing Crossing = iota
+	// CrossingNorthSouth carries the north-south passage over the bridge,
+	// and the east-west passage through the tunnel
+	CrossingNorthSouth
+	// CrossingEastWest carries the east-west passage over the bridge, and
+	// the north-south passage through the tunnel
+	CrossingEastWest
+)
+
+func (c Crossing) String() string {
+	switch c {
+	case NoCrossing:
+		return "None"
+	case CrossingNorthSouth:
+		return "North-South"
+	case CrossingEastWest:
+		return "East-West"
+	default:
+		return "Unknown"
+	}
+}
+
+// Crossing returns the crossing at the given position, or NoCrossing if
+// the cell is an ordinary one
+func (m *Maze) Crossing(x, y int) Crossing {
+	if m.crossings == nil || !m.IsValidPosition(x, y) {
+		return NoCrossing
+	}
+	i := (y*m.Width + x) * 2
+	c := NoCrossing
+	if m.crossings.get(i) {
+		c |= 1
+	}
+	if m.crossings.get(i + 1) {
+		c |= 2
+	}
+	return c
+}
+
+// SetCrossing makes the cell at the given position a crossing, or an
+// ordinary cell again with NoCrossing. Crossings have no walls, which is
+// up to the caller to remove
+func (m *Maze) SetCrossing(x, y int, crossing Crossing) {
+	if !m.IsValidPosition(x, y) {
+		return
+	}
+	if m.crossings == nil {
+		if crossing == NoCrossing {
+			return
+		}
+		m.crossings = newBitset(m.Width*m.Height*2, false)
+	}
+	i := (y*m.Width + x) * 2
+	m.crossings.set(i, crossing&1 != 0)
+	m.crossings.set(i+1, crossing&2 != 0)
+}
+
+// isCrossing reports whether the cell at the given position is a crossing
+func (m *Maze) isCrossing(x, y int) bool {
+	return m.Crossing(x, y) != NoCrossing
+}
+
+// step returns the cell reached by leaving (x, y) in the given direction,
+// passing straight through any crossings on the way
+func (m *Maze) step(x, y int, direction MazeDirection) (int, int) {
+	x, y = m.Neighbor(x, y, direction)
+	for m.isCrossing(x, y) {
+		x, y = m.Neighbor(x, y, direction)
+	}
+	return x, y
+}
+
+// crossingNeighbors returns the neighbors of a cell that could become a
+// crossing, as the two ends of the north-south passage followed by the two
+// ends of the east-west passage, and false if the cell cannot be one.
+// Crossings only go on plain square grids: where the grid wraps around, a
+// passage could run into crossings forever
+func (m *Maze) crossingNeighbors(x, y int) ([4]Position, bool) {
+	var ends [4]Position
+	if !m.isSquare() {
+		return ends, false
+	}
+	for i, dir := range [4]MazeDirection{North, South, East, West} {
+		if !m.hasDirection(x, y, dir) {
+			return ends, false
+		}
+		nx, ny := m.Neighbor(x, y, dir)
+		if !m.IsValidPosition(nx, ny) || m.isCrossing(nx, ny) {
+			return ends, false
+		}
+		ends[i] = Position{X: nx, Y: ny}
+	}
+	return ends, true
+}
+
+// checkCrossings returns an error for the first crossing a move could not
+// pass straight through: an unknown kind of crossing, one where no crossing
+// can go, or one with a wall across its passages. Mazes read from outside
+// are checked, as moves through such crossings may never end
+func (m *Maze) checkCrossings() error {
+	if m.crossings == nil {
+		return nil
+	}
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			crossing := m.Crossing(x, y)
+			if crossing == NoCrossing {
+				continue
+			}
+			if crossing != CrossingNorthSouth && crossing != CrossingEastWest {
+				return fmt.Errorf("cell (%d, %d) has unknown crossing %d", x, y, crossing)
+			}
+			if _, ok := m.crossingNeighbors(x, y); !ok {
+				return fmt.Errorf("crossing at (%d, %d) is not on a square grid between four ordinary cells", x, y)
+			}
+			for _, dir := range [4]MazeDirection{North, South, East, West} {
+				if m.HasWall(x, y, dir) {
+					return fmt.Errorf("crossing at (%d, %d) has a wall to the %s", x, y, dir)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// defaultWeaveDensity is the share of eligible cells WeaveGenerator tries
+// to turn into crossings when no density is given
+const defaultWeaveDensity = 0.3
+
+// WeaveGenerator implements a weave maze on top of Kruskal's algorithm:
+// passages may cross over or under each other. The crossings are laid
+// first, then the rest of the maze is carved around them. Only cells with
+// north, east, south and west walls can be crossings, so on other grids
+// this is plain Kruskal
+type WeaveGenerator struct {
+	// Density is the share of eligible cells, between 0 and 1, to try to
+	// turn into crossings; zero means defaultWeaveDensity
+	Density float64
+}
+
+func (WeaveGenerator) Name() string { return "Weave" }
+
+func (g WeaveGenerator) Generate(maze *Maze, rng *rand.Rand) {
+	density := g.Density
+	if density == 0 {
+		density = defaultWeaveDensity
+	}
+
+	var candidates []Position
+	for y := 0; y < maze.Height; y++ {
+		for x := 0; x < maze.Width; x++ {
+			if _, ok := maze.crossingNeighbors(x, y); maze.IsValidPosition(x, y) && ok {
+				candidates = append(candidates, Position{X: x, Y: y})
+			}
+		}
+	}
+	rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	sets := newDisjointSets(maze.Width * maze.Height)
+	index := func(p Position) int { return p.Y*maze.Width + p.X }
+	for _, cell := range candidates {
+		if rng.Float64() >= density {
+			continue
+		}
+		// Earlier crossings may have taken a neighbor
+		ends, ok := maze.crossingNeighbors(cell.X, cell.Y)
+		if !ok {
+			continue
+		}
+
+		// Both passages must join cells that are not connected yet, or
+		// they would close a loop
+		distinct := true
+		for i := range ends {
+			for j := i + 1; j < len(ends); j++ {
+				if sets.find(index(ends[i])) == sets.find(index(ends[j])) {
+					distinct = false
+				}
+			}
+		}
+		if !distinct {
+			continue
+		}
+
+		for _, dir := range [4]MazeDirection{North, South, East, West} {
+			maze.RemoveWall(cell.X, cell.Y, dir)
+		}
+		crossing := CrossingNorthSouth
+		if rng.Intn(2) == 0 {
+			crossing = CrossingEastWest
+		}
+		maze.SetCrossing(cell.X, cell.Y, crossing)
+		sets.union(index(ends[0]), index(ends[1]))
+		sets.union(index(ends[2]), index(ends[3]))
+		sets.union(index(cell), index(ends[0]))
+	}
+
+	// Carve the rest with Kruskal's algorithm, keeping away from crossings
+	type wall struct {
+		x, y int
+		dir  MazeDirection
+	}
+	var walls []wall
+	for y := 0; y < maze.Height; y++ {
+		for x := 0; x < maze.Width; x++ {
+			if !maze.IsValidPosition(x, y) || maze.isCrossing(x, y) {
+				continue
+			}
+			for _, dir := range maze.Directions(x, y) {
+				nx, ny := maze.Neighbor(x, y, dir)
+				if maze.IsValidPosition(nx, ny) && !maze.isCrossing(nx, ny) && ny*maze.Width+nx > y*maze.Width+x {
+					walls = append(walls, wall{x, y, dir})
+				}
+			}
+		}
+	}
+	rng.Shuffle(len(walls), func(i, j int) {
+		walls[i], walls[j] = walls[j], walls[i]
+	})
+	for _, w := range walls {
+		nx, ny := maze.Neighbor(w.x, w.y, w.dir)
+		if sets.union(w.y*maze.Width+w.x, ny*maze.Width+nx) {
+			maze.RemoveWall(w.x, w.y, w.dir)
+		}
+	}
+}
+
+// crossingInset is how far, as a fraction of the cell, the sides of a
+// bridge or tunnel are drawn from the walls of a crossing cell
+const crossingInset = 0.25
+
+// CrossingLines returns the lines to draw for a crossing cell, in cell
+// units: the two sides of the bridge running right across the cell, and the
+// stubs of the tunnel walls that disappear underneath it
+func CrossingLines(topology Topology, x, y int, crossing Crossing) [][]Point {
+	over, under := [2]MazeDirection{North, South}, [2]MazeDirection{East, West}
+	if crossing == CrossingEastWest {
+		over, under = under, over
+	}
+
+	// Walls run clockwise round the cell, so the same fraction along two
+	// opposite walls gives points on opposite sides
+	from, to := topology.Wall(x, y, over[0]), topology.Wall(x, y, over[1])
+	lines := [][]Point{
+		{pointAlong(from, crossingInset), pointAlong(to, 1-crossingInset)},
+		{pointAlong(from, 1-crossingInset), pointAlong(to, crossingInset)},
+	}
+	for i, dir := range under {
+		side, facing := topology.Wall(x, y, dir), topology.Wall(x, y, under[1-i])
+		for _, f := range [2]float64{crossingInset, 1 - crossingInset} {
+			start := pointAlong(side, f)
+			end := pointAlong(facing, 1-f)
+			lines = append(lines, []Point{start, {
+				X: start.X + (end.X-start.X)*crossingInset,
+				Y: start.Y + (end.Y-start.Y)*crossingInset,
+			}})
+		}
+	}
+	return lines
+}
+
+// pointAlong returns the point the given fraction of the way along a line
+// made of straight segments, treating every segment as the same length
+func pointAlong(line []Point, f float64) Point {
+	if len(line) == 1 {
+		return line[0]
+	}
+	pos := f * float64(len(line)-1)
+	i := min(int(pos), len(line)-2)
+	t := pos - float64(i)
+	return Point{
+		X: line[i].X + (line[i+1].X-line[i].X)*t,
+		Y: line[i].Y + (line[i+1].Y-line[i].Y)*t,
+	}
+}
@@ -0,0 +1,83 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCrossingMaze returns a 3x3 maze with a crossing in the middle, whose
+// passages lead out through the middle of each side
+func newCrossingMaze(crossing Crossing) *Maze {
+	maze := NewMaze(3, 3)
+	for _, dir := range []MazeDirection{North, East, South, West} {
+		maze.RemoveWall(1, 1, dir)
+	}
+	maze.SetCrossing(1, 1, crossing)
+	return maze
+}
+
+func TestMoveThroughCrossing(t *testing.T) {
+	maze := newCrossingMaze(CrossingNorthSouth)
+
+	next, ok := maze.Move(Position{1, 0}, South)
+	require.True(t, ok)
+	assert.Equal(t, Position{1, 2}, next, "over the bridge")
+
+	next, ok = maze.Move(Position{0, 1}, East)
+	require.True(t, ok)
+	assert.Equal(t, Position{2, 1}, next, "through the tunnel")
+
+	_, ok = maze.Move(Position{1, 0}, East)
+	assert.False(t, ok)
+}
+
+func TestShortestPathThroughCrossing(t *testing.T) {
+	maze := newCrossingMaze(CrossingEastWest)
+	maze.RemoveWall(2, 1, East)
+
+	path, ok := maze.ShortestPath(Position{0, 1})
+	require.True(t, ok)
+	assert.Equal(t, Path{
+		Positions:  []Position{{0, 1}, {2, 1}},
+		Directions: []MazeDirection{East, East},
+	}, path)
+
+	distances := maze.DistanceMap(Position{0, 1})
+	assert.Equal(t, -1, distances[1][1], "crossings are passed through")
+	assert.Equal(t, 1, distances[1][2])
+}
+
+func TestWeaveGenerator(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		maze := NewMaze(12, 12)
+		WeaveGenerator{Density: 1}.Generate(maze, rand.New(rand.NewSource(seed)))
+		assertPerfectMaze(t, maze)
+
+		crossings := 0
+		for y := 0; y < maze.Height; y++ {
+			for x := 0; x < maze.Width; x++ {
+				if !maze.isCrossing(x, y) {
+					continue
+				}
+				crossings++
+				for _, dir := range maze.Directions(x, y) {
+					assert.False(t, maze.HasWall(x, y, dir), "crossings have no walls")
+					nx, ny := maze.Neighbor(x, y, dir)
+					assert.False(t, maze.isCrossing(nx, ny), "crossings are never side by side")
+				}
+			}
+		}
+		assert.Positive(t, crossings, "seed %d", seed)
+	}
+}
+
+func TestCrossingLines(t *testing.T) {
+	lines := CrossingLines(SquareTopology{}, 1, 1, CrossingNorthSouth)
+	require.Len(t, lines, 6)
+	assert.Equal(t, []Point{{1.25, 1}, {1.25, 2}}, lines[0], "bridge side")
+	assert.Equal(t, []Point{{1.75, 1}, {1.75, 2}}, lines[1], "bridge side")
+	assert.Equal(t, []Point{{2, 1.25}, {1.75, 1.25}}, lines[2], "tunnel wall from the east")
+}
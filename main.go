@@ -1,11 +1,12 @@
 package main
 
 import (
+	"flag"
 	"log"
 
 	"github.com/bfreis/ebitentools/ebitenwrap"
 	"github.com/bfreis/ebitentools/ebitenwrapfx"
-	"github.com/bfreis/trijam-304/game"
+	"github.com/bfreis/trijam-304/ui"
 	"github.com/hajimehoshi/ebiten/v2"
 	"go.uber.org/fx"
 )
@@ -15,10 +16,14 @@ func main() {
 }
 
 func run() {
+	var options ui.Options
+	flag.StringVar(&options.LevelDir, "levels", "", "directory of extra level packs, one subdirectory per pack")
+	flag.Parse()
+
 	var g *ebitenwrap.Wrapper
 
 	fxApp := fx.New(
-		bootstrap(),
+		bootstrap(options),
 		fx.Populate(&g),
 	)
 	err := fxApp.Err()
@@ -39,10 +44,11 @@ func run() {
 	}
 }
 
-func bootstrap() fx.Option {
+func bootstrap(options ui.Options) fx.Option {
 	return fx.Options(
-		game.Module,
+		fx.Supply(options),
+		ui.Module,
 		ebitenwrapfx.Module,
-		fx.Provide(func(g *game.Game) ebitenwrap.Game { return g }),
+		fx.Provide(func(g *ui.Game) ebitenwrap.Game { return g }),
 	)
 }
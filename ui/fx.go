@@ -1,8 +1,8 @@
-package game
+package ui
 
 import "go.uber.org/fx"
 
-var Module = fx.Module("game",
+var Module = fx.Module("ui",
 	fx.Provide(
 		NewGame,
 	),
@@ -0,0 +1,255 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/bfreis/ebitentools/ebitenwrap"
+	"github.com/bfreis/trijam-304/game"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func isButtonJustReleased(input ebitenwrap.InputState) bool {
+	// Check keyboard
+	if input.Keyboard().IsKeyJustReleased(ebiten.KeyEnter) {
+		return true
+	}
+
+	// Check mouse - left button
+	if input.Mouse().IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
+		return true
+	}
+
+	// Check touch - any touch release counts
+	touchIDs := input.Touch().AppendJustReleasedTouchIDs(nil)
+	return len(touchIDs) > 0
+}
+
+type Game struct {
+	currentScreen       ScreenType
+	titleScreen         *TitleScreen
+	mazeScreen          *MazeScreen
+	aboutScreen         *AboutScreen
+	levelSelectScreen   *LevelSelectScreen
+	levelCompleteScreen *LevelCompleteScreen
+	// playing is the transition that started the maze on mazeScreen, to
+	// know what was won and what comes next
+	playing  *ScreenTransition
+	store    game.Store
+	campaign game.Campaign
+	progress game.CampaignProgress
+	daily    game.DailyRecords
+}
+
+// Options configures the game
+type Options struct {
+	// LevelDir is a directory of level packs to offer next to the built-in
+	// ones, or empty for none
+	LevelDir string
+}
+
+func NewGame(options Options) (*Game, error) {
+	packs, err := game.BuiltinLevelPacks()
+	if err != nil {
+		return nil, err
+	}
+	if options.LevelDir != "" {
+		extra, err := game.LoadLevelPacks(os.DirFS(options.LevelDir))
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, extra...)
+	}
+
+	// Progress that cannot be read is lost, but should not stop the game
+	store := game.DefaultStore()
+	campaign := game.DefaultCampaign()
+	progress, err := campaign.LoadProgress(store)
+	if err != nil {
+		log.Printf("loading campaign progress: %v", err)
+	}
+	daily, err := game.LoadDailyRecords(store)
+	if err != nil {
+		log.Printf("loading daily challenge results: %v", err)
+	}
+
+	initial := &ScreenTransition{
+		NextScreen:  ScreenMaze,
+		PlayerSpeed: game.SpeedMedium,
+		MazeSize:    game.SizeMedium,
+		Seed:        game.NewSeed(),
+	}
+	mazeScreen, err := NewMazeScreen(initial.PlayerSpeed, initial.MazeConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Game{
+		currentScreen: ScreenTitle,
+		titleScreen:   NewTitleScreen(packs),
+		mazeScreen:    mazeScreen,
+		aboutScreen:   NewAboutScreen(),
+		playing:       initial,
+		store:         store,
+		campaign:      campaign,
+		progress:      progress,
+		daily:         daily,
+	}, nil
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	switch g.currentScreen {
+	case ScreenTitle:
+		g.titleScreen.Draw(screen)
+	case ScreenMaze:
+		g.mazeScreen.Draw(screen)
+	case ScreenAbout:
+		g.aboutScreen.Draw(screen)
+	case ScreenLevelSelect:
+		g.levelSelectScreen.Draw(screen)
+	case ScreenLevelComplete:
+		g.levelCompleteScreen.Draw(screen)
+	}
+}
+
+func (g *Game) Update(tick ebitenwrap.Tick) error {
+	var err error
+	var transition *ScreenTransition
+
+	switch g.currentScreen {
+	case ScreenTitle:
+		transition, err = g.titleScreen.Update(tick)
+	case ScreenMaze:
+		transition, err = g.mazeScreen.Update(tick)
+	case ScreenAbout:
+		transition, err = g.aboutScreen.Update(tick)
+	case ScreenLevelSelect:
+		transition, err = g.levelSelectScreen.Update(tick)
+	case ScreenLevelComplete:
+		transition, err = g.levelCompleteScreen.Update(tick)
+	}
+	if err != nil || transition == nil {
+		return err
+	}
+	return g.switchTo(transition)
+}
+
+// switchTo moves on to the screen the transition asks for, setting it up
+// when it depends on where the player comes from
+func (g *Game) switchTo(transition *ScreenTransition) error {
+	switch transition.NextScreen {
+	case ScreenMaze:
+		if transition.Campaign != nil {
+			// Campaign levels are played against the game's own progress
+			transition.Campaign = &g.campaign
+		}
+		if transition.Daily != nil {
+			// Only the first run of the day counts, later ones are practice
+			_, transition.Practice = g.daily[transition.Daily.Date]
+		}
+		mazeScreen, err := transition.NewMazeScreen()
+		if err != nil {
+			return err
+		}
+		g.mazeScreen = mazeScreen
+		g.playing = transition
+	case ScreenLevelSelect:
+		g.levelSelectScreen = NewLevelSelectScreen(&g.campaign, g.progress)
+	case ScreenLevelComplete:
+		g.levelCompleteScreen = g.completeLevel()
+	}
+	g.currentScreen = transition.NextScreen
+	return nil
+}
+
+// completeLevel records the maze just won, and returns the screen offering
+// the ways on from it: the next level if there is one, the same maze again,
+// and back to where the player came from
+func (g *Game) completeLevel() *LevelCompleteScreen {
+	played := g.playing
+	replay := *played
+	title := levelChoice{label: "Title", transition: &ScreenTransition{NextScreen: ScreenTitle}}
+	run := []string{g.mazeScreen.statsText(), fmt.Sprintf("Score: %d", g.mazeScreen.Score())}
+
+	switch {
+	case played.Campaign != nil:
+		level := played.CampaignLevel
+		details := append([]string{fmt.Sprintf("Campaign level %d/%d: %s", level+1, len(g.campaign.Levels), g.campaign.Levels[level].Name)}, run...)
+		unlocked, err := g.campaign.Complete(&g.progress, level)
+		if err != nil {
+			log.Printf("completing campaign level: %v", err)
+		}
+		if err := g.campaign.SaveProgress(g.store, g.progress); err != nil {
+			log.Printf("saving campaign progress: %v", err)
+		}
+		switch {
+		case unlocked:
+			details = append(details, "Next level unlocked!")
+		case level == len(g.campaign.Levels)-1:
+			details = append(details, "Campaign complete!")
+		}
+
+		var choices []levelChoice
+		if g.campaign.IsUnlocked(g.progress, level+1) {
+			next := replay
+			next.CampaignLevel = level + 1
+			choices = append(choices, levelChoice{label: "Next Level", transition: &next})
+		}
+		choices = append(choices,
+			levelChoice{label: "Replay", transition: &replay},
+			levelChoice{label: "Level Select", transition: &ScreenTransition{NextScreen: ScreenLevelSelect}},
+			title)
+		return NewLevelCompleteScreen("LEVEL COMPLETE", details, choices)
+
+	case played.Level != nil:
+		var choices []levelChoice
+		for i := range played.Pack.Levels {
+			if &played.Pack.Levels[i] == played.Level && i+1 < len(played.Pack.Levels) {
+				next := replay
+				next.Level = &played.Pack.Levels[i+1]
+				choices = append(choices, levelChoice{label: "Next Level", transition: &next})
+			}
+		}
+		choices = append(choices, levelChoice{label: "Replay", transition: &replay}, title)
+		details := append([]string{fmt.Sprintf("%s: %s", played.Pack.Name, played.Level.Name)}, run...)
+		if par := played.Level.Par; par > 0 {
+			details = append(details, fmt.Sprintf("Par: %s", par))
+		}
+		return NewLevelCompleteScreen("LEVEL COMPLETE", details, choices)
+
+	case played.Daily != nil:
+		date := played.Daily.Date
+		stats := g.mazeScreen.stats
+		result := game.DailyResult{Time: stats.Elapsed(), Presses: stats.Presses, Version: played.Daily.Version}
+		details := append([]string{"Daily challenge " + date}, run...)
+		if g.daily.Record(date, result) {
+			official := g.daily[date]
+			details = append(details, fmt.Sprintf("Practice run, the recorded result is %s with %d presses",
+				official.Time.Round(100*time.Millisecond), official.Presses))
+		} else {
+			details = append(details, "Result recorded")
+			if err := game.SaveDailyRecords(g.store, g.daily); err != nil {
+				log.Printf("saving daily challenge results: %v", err)
+			}
+		}
+		choices := []levelChoice{{label: "Practice", transition: &replay}, title}
+		return NewLevelCompleteScreen("DAILY COMPLETE", details, choices)
+
+	default:
+		another := replay
+		another.Seed = game.NewSeed()
+		choices := []levelChoice{
+			{label: "New Maze", transition: &another},
+			{label: "Replay", transition: &replay},
+			title,
+		}
+		details := append([]string{fmt.Sprintf("Seed: %d", played.Seed)}, run...)
+		return NewLevelCompleteScreen("YOU WON!", details, choices)
+	}
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	return outsideWidth, outsideHeight
+}
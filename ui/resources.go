@@ -1,4 +1,4 @@
-package game
+package ui
 
 import (
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
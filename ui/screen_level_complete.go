@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/bfreis/ebitentools/ebitenwrap"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+const headingScale = 3.0
+
+// levelChoice is one of the ways on from the level complete screen
+type levelChoice struct {
+	label      string
+	transition *ScreenTransition
+}
+
+// LevelCompleteScreen congratulates the player on the maze just won and
+// offers the ways on, which take turns being selected like the options of
+// the title screen
+type LevelCompleteScreen struct {
+	heading        string
+	details        []string
+	choices        []levelChoice
+	selectedChoice int
+	tickCounter    int
+}
+
+func NewLevelCompleteScreen(heading string, details []string, choices []levelChoice) *LevelCompleteScreen {
+	return &LevelCompleteScreen{
+		heading: heading,
+		details: details,
+		choices: choices,
+	}
+}
+
+func (s *LevelCompleteScreen) Update(tick ebitenwrap.Tick) (*ScreenTransition, error) {
+	if tick.InputState.Keyboard().IsKeyJustPressed(ebiten.KeyEscape) {
+		return &ScreenTransition{
+			NextScreen: ScreenTitle,
+		}, nil
+	}
+
+	s.tickCounter++
+	if s.tickCounter >= tick.TPS { // Switch every second
+		s.selectedChoice = (s.selectedChoice + 1) % len(s.choices)
+		s.tickCounter = 0
+	}
+	if isButtonJustReleased(tick.InputState) {
+		return s.choices[s.selectedChoice].transition, nil
+	}
+	return nil, nil
+}
+
+func (s *LevelCompleteScreen) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{40, 40, 40, 255})
+	sw := screen.Bounds().Dx()
+
+	opts := &text.DrawOptions{}
+	opts.GeoM.Scale(headingScale, headingScale)
+	opts.GeoM.Translate(
+		float64(sw)/2-float64(len(s.heading)*7)*headingScale/2, // Approximate width based on monospace font
+		150,
+	)
+	opts.ColorScale.Scale(1, 1, 0, 1) // Yellow
+	text.Draw(screen, s.heading, face7x13, opts)
+
+	for i, line := range s.details {
+		opts := &text.DrawOptions{}
+		opts.GeoM.Translate(float64(sw)/2-float64(len(line)*7)/2, float64(250+i*25))
+		text.Draw(screen, line, face7x13, opts)
+	}
+
+	for i, choice := range s.choices {
+		opts := &text.DrawOptions{}
+		opts.GeoM.Translate(300, float64(400+i*40))
+		if i == s.selectedChoice {
+			opts.ColorScale.Scale(1, 1, 0, 1) // Yellow
+			text.Draw(screen, "> "+choice.label, face7x13, opts)
+		} else {
+			text.Draw(screen, "  "+choice.label, face7x13, opts)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/bfreis/ebitentools/ebitenwrap"
+	"github.com/bfreis/trijam-304/game"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// LevelSelectScreen lists the levels of the campaign. The unlocked ones,
+// and a way back to the title, take turns being selected
+type LevelSelectScreen struct {
+	campaign       *game.Campaign
+	progress       game.CampaignProgress
+	selectedOption int // Index of the selected level, or len(levels) for Back
+	tickCounter    int
+}
+
+func NewLevelSelectScreen(campaign *game.Campaign, progress game.CampaignProgress) *LevelSelectScreen {
+	s := &LevelSelectScreen{
+		campaign: campaign,
+		progress: progress,
+	}
+	// Start on the furthest level unlocked, which is most likely the one
+	// the player wants next
+	for i := range campaign.Levels {
+		if campaign.IsUnlocked(progress, i) {
+			s.selectedOption = i
+		}
+	}
+	return s
+}
+
+func (s *LevelSelectScreen) Update(tick ebitenwrap.Tick) (*ScreenTransition, error) {
+	if tick.InputState.Keyboard().IsKeyJustPressed(ebiten.KeyEscape) {
+		return &ScreenTransition{
+			NextScreen: ScreenTitle,
+		}, nil
+	}
+
+	s.tickCounter++
+	if s.tickCounter >= tick.TPS { // Switch every second
+		// Skip the locked levels, which follow the unlocked ones
+		s.selectedOption++
+		if s.selectedOption < len(s.campaign.Levels) && !s.campaign.IsUnlocked(s.progress, s.selectedOption) {
+			s.selectedOption = len(s.campaign.Levels)
+		}
+		s.selectedOption %= len(s.campaign.Levels) + 1
+		s.tickCounter = 0
+	}
+	if isButtonJustReleased(tick.InputState) {
+		if s.selectedOption == len(s.campaign.Levels) {
+			return &ScreenTransition{
+				NextScreen: ScreenTitle,
+			}, nil
+		}
+		return &ScreenTransition{
+			NextScreen:    ScreenMaze,
+			Campaign:      s.campaign,
+			CampaignLevel: s.selectedOption,
+		}, nil
+	}
+	return nil, nil
+}
+
+func (s *LevelSelectScreen) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{40, 40, 40, 255})
+
+	opts := &text.DrawOptions{}
+	opts.GeoM.Translate(300, 100)
+	text.Draw(screen, fmt.Sprintf("Campaign: %d/%d cleared", min(s.progress.Cleared, len(s.campaign.Levels)), len(s.campaign.Levels)), face7x13, opts)
+
+	for i := 0; i <= len(s.campaign.Levels); i++ {
+		opts := &text.DrawOptions{}
+		opts.GeoM.Translate(200, float64(160+i*40))
+
+		var menuText string
+		switch {
+		case i == len(s.campaign.Levels):
+			menuText = "Back"
+		case !s.campaign.IsUnlocked(s.progress, i):
+			menuText = fmt.Sprintf("%2d. Locked", i+1)
+			opts.ColorScale.Scale(0.5, 0.5, 0.5, 1) // Gray
+		default:
+			level := s.campaign.Levels[i]
+			menuText = fmt.Sprintf("%2d. %-16s %dx%d  Speed: %s  %s", i+1, level.Name, level.Width, level.Height, level.Speed, level.Algorithm)
+			if i < s.progress.Cleared {
+				menuText += "  Cleared"
+			}
+		}
+
+		if i == s.selectedOption {
+			opts.ColorScale.Scale(1, 1, 0, 1) // Yellow
+			text.Draw(screen, "> "+menuText, face7x13, opts)
+		} else {
+			text.Draw(screen, "  "+menuText, face7x13, opts)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/bfreis/ebitentools/ebitenwrap"
+	"github.com/bfreis/trijam-304/game"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	mazeCellDisplaySize = 32
+	wallThickness       = 2.0
+	floorMapScale       = 0.25 // Size of the maps of the floors above and below
+)
+
+type MazeScreen struct {
+	tower                  *game.Tower
+	maze                   *game.Maze // The floor the player is on
+	floor                  int
+	playerX                int
+	playerY                int
+	playerDirection        game.MazeDirection
+	directionIndex         int // Index of playerDirection in the cell's directions
+	ticksSinceLastRotation int
+	hasWon                 bool
+	ticksSinceWin          int
+	exitDirection          game.MazeDirection // Direction where player exited the maze
+	playerSpeed            game.PlayerSpeed
+	info                   string // Shown in the corner, to tell mazes apart
+	stats                  game.RunStats
+	wayOut                 game.MazeDirection // First step of the shortest way out
+	hasWayOut              bool
+	practice               bool // Whether the run does not count, shown on the HUD
+}
+
+func NewMazeScreen(playerSpeed game.PlayerSpeed, config game.MazeConfig) (*MazeScreen, error) {
+	tower := config.GenerateTower()
+
+	// Show seed and algorithm so the maze can be reproduced
+	info := fmt.Sprintf("Seed: %d  Algorithm: %s", config.Seed, tower.Floors[0].Generator)
+	if config.Grid != game.GridSquare {
+		info += fmt.Sprintf("  Grid: %s", config.Grid)
+	}
+	if config.Braid > 0 {
+		info += fmt.Sprintf("  Loops: %.0f%%", config.Braid*100)
+	}
+	return newMazeScreen(playerSpeed, tower, info), nil
+}
+
+// NewMazeScreenFromMaze starts a screen on a prebuilt maze, such as a level
+// or one read with game.ParseMaze, shown under the given name. Mazes that
+// cannot be played are refused
+func NewMazeScreenFromMaze(playerSpeed game.PlayerSpeed, maze *game.Maze, name string) (*MazeScreen, error) {
+	if err := maze.Validate(maze.Start).Err(); err != nil {
+		return nil, fmt.Errorf("invalid maze: %w", err)
+	}
+	return newMazeScreen(playerSpeed, game.NewTower(maze), name), nil
+}
+
+func newMazeScreen(playerSpeed game.PlayerSpeed, tower *game.Tower, info string) *MazeScreen {
+	start := tower.Start
+	s := &MazeScreen{
+		tower:                  tower,
+		maze:                   tower.Floors[start.Floor],
+		floor:                  start.Floor,
+		playerX:                start.X,
+		playerY:                start.Y,
+		playerDirection:        tower.Directions(start)[0],
+		ticksSinceLastRotation: 0,
+		hasWon:                 false,
+		playerSpeed:            playerSpeed,
+		info:                   info,
+	}
+	s.findWayOut()
+	return s
+}
+
+func (s *MazeScreen) Update(tick ebitenwrap.Tick) (*ScreenTransition, error) {
+	if tick.InputState.Keyboard().IsKeyJustPressed(ebiten.KeyEscape) {
+		return &ScreenTransition{
+			NextScreen: ScreenTitle,
+		}, nil
+	}
+
+	if s.hasWon {
+		// Let the winning move show for a moment before moving on
+		s.ticksSinceWin++
+		if s.ticksSinceWin >= tick.TPS/2 || isButtonJustReleased(tick.InputState) {
+			return &ScreenTransition{
+				NextScreen: ScreenLevelComplete,
+			}, nil
+		}
+		return nil, nil
+	}
+
+	s.stats.TPS = tick.TPS
+	s.stats.Ticks++
+
+	// Rotate player direction based on player speed
+	s.ticksSinceLastRotation++
+	rotationTicks := s.playerSpeed.RotationTicks(tick.TPS)
+	if s.ticksSinceLastRotation >= rotationTicks {
+		if s.hasWayOut && s.playerDirection == s.wayOut {
+			s.stats.MissedRotations++
+		}
+		directions := s.tower.Directions(s.location())
+		s.directionIndex = (s.directionIndex + 1) % len(directions)
+		s.playerDirection = directions[s.directionIndex]
+		s.ticksSinceLastRotation = 0
+	}
+
+	// Move player when button is released
+	if isButtonJustReleased(tick.InputState) {
+		s.stats.Presses++
+		next, ok := s.tower.Move(s.location(), s.playerDirection)
+		if ok {
+			s.stats.Moves++
+		} else {
+			s.stats.Bumps++
+		}
+
+		// Check if movement would lead to winning
+		if ok && !s.tower.IsValidLocation(next) {
+			s.hasWon = true
+			s.exitDirection = s.playerDirection
+			return nil, nil
+		}
+
+		// Mazes without an outer wall are won by reaching the goal instead
+		if ok && s.tower.IsGoal(next) {
+			s.hasWon = true
+			s.playerX = next.X
+			s.playerY = next.Y
+			return nil, nil
+		}
+
+		// Check if movement is valid (no wall in the way)
+		if ok {
+			s.floor = next.Floor
+			s.maze = s.tower.Floors[next.Floor]
+			s.playerX = next.X
+			s.playerY = next.Y
+
+			// Cells with fewer walls keep the player facing the same way round
+			directions := s.tower.Directions(next)
+			s.directionIndex %= len(directions)
+			s.playerDirection = directions[s.directionIndex]
+			s.findWayOut()
+		}
+	}
+
+	return nil, nil
+}
+
+// findWayOut works out which way the shortest way out leaves the player's
+// cell, to tell when the player lets it rotate past
+func (s *MazeScreen) findWayOut() {
+	_, directions, ok := s.tower.ShortestPath(s.location())
+	s.hasWayOut = ok && len(directions) > 0
+	if s.hasWayOut {
+		s.wayOut = directions[0]
+	}
+}
+
+// Score returns the points the run through the maze so far is worth
+func (s *MazeScreen) Score() int {
+	return s.stats.Score(s.tower.Cells(), s.playerSpeed)
+}
+
+// statsText describes the run through the maze so far
+func (s *MazeScreen) statsText() string {
+	return fmt.Sprintf("Time: %s  Presses: %d  Moves: %d  Bumps: %d  Missed: %d",
+		s.stats.Elapsed().Round(100*time.Millisecond), s.stats.Presses, s.stats.Moves, s.stats.Bumps, s.stats.MissedRotations)
+}
+
+// location returns where the player is in the tower
+func (s *MazeScreen) location() game.Location {
+	return game.Location{Floor: s.floor, Position: game.Position{X: s.playerX, Y: s.playerY}}
+}
+
+func (s *MazeScreen) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{40, 40, 40, 255})
+
+	topology := s.maze.Geometry()
+	unitsX, unitsY := topology.Size(s.maze.Width, s.maze.Height)
+
+	// Cells are drawn at full size unless that would not fit on the screen
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	cellSize := min(float64(mazeCellDisplaySize), float64(sw)*0.9/unitsX, float64(sh)*0.9/unitsY)
+
+	mazeWidth := unitsX * cellSize
+	mazeHeight := unitsY * cellSize
+	offsetX := (float64(sw) - mazeWidth) / 2
+	offsetY := (float64(sh) - mazeHeight) / 2
+
+	// toScreen converts a point in cell units to screen coordinates
+	toScreen := func(p game.Point) (float32, float32) {
+		return float32(offsetX + p.X*cellSize), float32(offsetY + p.Y*cellSize)
+	}
+
+	// Draw maze walls
+	drawFloor(screen, s.maze, offsetX, offsetY, cellSize, color.White)
+
+	// Draw maps of the floors above and below in the corners, with the
+	// stairs leading to them marked on this floor
+	mapSize := float64(min(sw, sh)) * floorMapScale
+	mapCellSize := min(mapSize/unitsX, mapSize/unitsY)
+	if s.floor+1 < len(s.tower.Floors) {
+		drawFloor(screen, s.tower.Floors[s.floor+1], float64(sw)-mapSize-10, 30, mapCellSize, color.Gray{100})
+	}
+	if s.floor > 0 {
+		drawFloor(screen, s.tower.Floors[s.floor-1], float64(sw)-mapSize-10, float64(sh)-mapSize-10, mapCellSize, color.Gray{100})
+	}
+	for y := 0; y < s.maze.Height; y++ {
+		for x := 0; x < s.maze.Width; x++ {
+			if !s.maze.IsValidPosition(x, y) {
+				continue
+			}
+			cx, cy := toScreen(topology.Center(x, y))
+			arrow := float32(cellSize) / 6
+			if !s.maze.HasWall(x, y, game.Up) {
+				vector.StrokeLine(screen, cx-arrow, cy, cx, cy-arrow, wallThickness, stairsUpColor, false)
+				vector.StrokeLine(screen, cx, cy-arrow, cx+arrow, cy, wallThickness, stairsUpColor, false)
+			}
+			if !s.maze.HasWall(x, y, game.Down) {
+				vector.StrokeLine(screen, cx-arrow, cy, cx, cy+arrow, wallThickness, stairsDownColor, false)
+				vector.StrokeLine(screen, cx, cy+arrow, cx+arrow, cy, wallThickness, stairsDownColor, false)
+			}
+		}
+	}
+
+	// Draw the goal of mazes without an outer wall
+	if s.maze.Goal != nil {
+		gx, gy := toScreen(topology.Center(s.maze.Goal.X, s.maze.Goal.Y))
+		vector.DrawFilledCircle(screen, gx, gy, float32(cellSize)/3, goalColor, false)
+	}
+
+	// Calculate player position, adjusting for win state
+	playerX, playerY := s.playerX, s.playerY
+	if s.hasWon && !s.tower.IsGoal(s.location()) {
+		playerX, playerY = s.maze.Neighbor(playerX, playerY, s.exitDirection)
+	}
+
+	// Draw player
+	playerRadius := float32(cellSize) / 4
+	playerPosX, playerPosY := toScreen(topology.Center(playerX, playerY))
+
+	// Draw player body
+	vector.DrawFilledCircle(screen, playerPosX, playerPosY, playerRadius, color.RGBA{255, 200, 0, 255}, false)
+
+	// Draw direction indicator, pointing at the middle of the wall the
+	// player faces, or a ring round the player when facing the stairs
+	switch s.playerDirection {
+	case game.Up:
+		vector.StrokeCircle(screen, playerPosX, playerPosY, playerRadius*1.5, wallThickness, stairsUpColor, false)
+	case game.Down:
+		vector.StrokeCircle(screen, playerPosX, playerPosY, playerRadius*1.5, wallThickness, stairsDownColor, false)
+	}
+	indicatorLength := float64(playerRadius) * 1.2
+	center := topology.Center(s.playerX, s.playerY)
+	wall := topology.Wall(s.playerX, s.playerY, s.playerDirection)
+	var dx, dy float32
+	if len(wall) > 0 {
+		first, last := wall[0], wall[len(wall)-1]
+		vx := (first.X+last.X)/2 - center.X
+		vy := (first.Y+last.Y)/2 - center.Y
+		if length := math.Hypot(vx, vy); length > 0 {
+			dx = float32(vx / length * indicatorLength)
+			dy = float32(vy / length * indicatorLength)
+		}
+	}
+	vector.StrokeLine(screen,
+		playerPosX, playerPosY,
+		playerPosX+dx, playerPosY+dy,
+		wallThickness*2, color.RGBA{255, 100, 0, 255}, false)
+
+	// Draw what maze this is
+	seedOpts := &text.DrawOptions{}
+	seedOpts.GeoM.Translate(10, 10)
+	seedOpts.ColorScale.Scale(0.6, 0.6, 0.6, 1) // Gray
+	info := s.info
+	if len(s.tower.Floors) > 1 {
+		info += fmt.Sprintf("  Floor: %d/%d", s.floor+1, len(s.tower.Floors))
+	}
+	text.Draw(screen, info, face7x13, seedOpts)
+
+	// Draw the HUD with how the run is going
+	hudOpts := &text.DrawOptions{}
+	hudOpts.GeoM.Translate(10, float64(sh)-25)
+	hud := s.statsText()
+	if s.practice {
+		hud = "Practice  " + hud
+	}
+	text.Draw(screen, hud, face7x13, hudOpts)
+}
+
+var (
+	stairsUpColor   = color.RGBA{0, 200, 100, 255}
+	stairsDownColor = color.RGBA{80, 140, 255, 255}
+	goalColor       = color.RGBA{0, 180, 0, 255}
+	wrapColor       = color.RGBA{200, 80, 200, 255}
+)
+
+// drawFloor draws the walls of a maze with its top-left corner at the given
+// offset and cells of the given size. Passages that wrap around the edges
+// of the maze are marked in wrapColor on both sides
+func drawFloor(screen *ebiten.Image, maze *game.Maze, offsetX, offsetY, cellSize float64, clr color.Color) {
+	topology := maze.Geometry()
+	drawLine := func(line []game.Point, clr color.Color) {
+		for i := 1; i < len(line); i++ {
+			x0, y0 := float32(offsetX+line[i-1].X*cellSize), float32(offsetY+line[i-1].Y*cellSize)
+			x1, y1 := float32(offsetX+line[i].X*cellSize), float32(offsetY+line[i].Y*cellSize)
+			vector.StrokeLine(screen, x0, y0, x1, y1, float32(wallThickness), clr, false)
+		}
+	}
+
+	for y := 0; y < maze.Height; y++ {
+		for x := 0; x < maze.Width; x++ {
+			if !maze.IsValidPosition(x, y) {
+				continue // Masked out, not part of the maze
+			}
+			if crossing := maze.Crossing(x, y); crossing != game.NoCrossing {
+				// Draw the bridge, and the tunnel walls going under it
+				for _, line := range game.CrossingLines(topology, x, y, crossing) {
+					drawLine(line, clr)
+				}
+				continue
+			}
+			for _, dir := range maze.Directions(x, y) {
+				if maze.HasWall(x, y, dir) {
+					drawLine(topology.Wall(x, y, dir), clr)
+				} else if game.IsWrapped(topology, x, y, dir) {
+					drawLine(topology.Wall(x, y, dir), wrapColor)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/bfreis/ebitentools/ebitenwrap"
+	"github.com/bfreis/trijam-304/game"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+type TitleScreen struct {
+	selectedOption int
+	options        []string
+	playerSpeed    game.PlayerSpeed
+	mazeSize       game.MazeSize
+	mazeShape      game.MazeShape
+	grid           game.Grid
+	floors         game.Floors
+	algorithm      game.MazeAlgorithm
+	loopDensity    game.LoopDensity
+	difficulty     game.Difficulty
+	levels         []titleLevel
+	level          int // Index in levels of the level to play, or -1 for a random maze
+	tickCounter    int
+}
+
+// titleLevel is one of the levels the title screen offers
+type titleLevel struct {
+	pack  *game.LevelPack
+	index int
+}
+
+func NewTitleScreen(packs []*game.LevelPack) *TitleScreen {
+	var levels []titleLevel
+	for _, pack := range packs {
+		for i := range pack.Levels {
+			levels = append(levels, titleLevel{pack: pack, index: i})
+		}
+	}
+
+	return &TitleScreen{
+		selectedOption: 0,
+		options:        []string{"Start", "Campaign", "Daily", "Levels", "Player Speed", "Maze Size", "Shape", "Grid", "Floors", "Algorithm", "Loops", "Difficulty", "About"},
+		playerSpeed:    game.SpeedMedium,
+		mazeSize:       game.SizeMedium,
+		mazeShape:      game.ShapeRectangle,
+		grid:           game.GridSquare,
+		floors:         game.FloorsOne,
+		algorithm:      game.AlgorithmBacktracker,
+		loopDensity:    game.LoopsNone,
+		difficulty:     game.DifficultyAny,
+		levels:         levels,
+		level:          -1,
+		tickCounter:    0,
+	}
+}
+
+func (s *TitleScreen) Update(tick ebitenwrap.Tick) (*ScreenTransition, error) {
+	s.tickCounter++
+	if s.tickCounter >= tick.TPS { // Switch every second
+		s.selectedOption = (s.selectedOption + 1) % len(s.options)
+		s.tickCounter = 0
+	}
+	if isButtonJustReleased(tick.InputState) {
+		switch s.options[s.selectedOption] {
+		case "Levels":
+			// Go through every level of every pack, then back to random
+			s.level++
+			if s.level == len(s.levels) {
+				s.level = -1
+			}
+		case "Player Speed":
+			s.playerSpeed = s.playerSpeed.Next()
+		case "Maze Size":
+			s.mazeSize = s.mazeSize.Next()
+		case "Shape":
+			s.mazeShape = s.mazeShape.Next()
+		case "Grid":
+			s.grid = s.grid.Next()
+		case "Floors":
+			s.floors = s.floors.Next()
+		case "Algorithm":
+			s.algorithm = s.algorithm.Next()
+		case "Loops":
+			s.loopDensity = s.loopDensity.Next()
+		case "Difficulty":
+			s.difficulty = s.difficulty.Next()
+		case "Start":
+			if s.level >= 0 {
+				choice := s.levels[s.level]
+				return &ScreenTransition{
+					NextScreen: ScreenMaze,
+					Level:      &choice.pack.Levels[choice.index],
+					Pack:       choice.pack,
+				}, nil
+			}
+			return &ScreenTransition{
+				NextScreen:  ScreenMaze,
+				PlayerSpeed: s.playerSpeed,
+				MazeSize:    s.mazeSize,
+				MazeShape:   s.mazeShape,
+				Grid:        s.grid,
+				Floors:      s.floors,
+				Seed:        game.NewSeed(),
+				Algorithm:   s.algorithm,
+				LoopDensity: s.loopDensity,
+				Difficulty:  s.difficulty,
+			}, nil
+		case "Campaign":
+			return &ScreenTransition{
+				NextScreen: ScreenLevelSelect,
+			}, nil
+		case "Daily":
+			daily := game.DailyFor(time.Now())
+			return &ScreenTransition{
+				NextScreen: ScreenMaze,
+				Daily:      &daily,
+			}, nil
+		case "About":
+			return &ScreenTransition{
+				NextScreen: ScreenAbout,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *TitleScreen) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{40, 40, 40, 255})
+
+	// Options go 40 apart below the title, and everything moves up and
+	// closer together when the window is too short for them
+	top, spacing := 300.0, 40.0
+	if height := top + spacing*float64(len(s.options)); height > float64(screen.Bounds().Dy()) {
+		scale := float64(screen.Bounds().Dy()) / height
+		top, spacing = top*scale, spacing*scale
+	}
+
+	// Draw title
+	opts := &text.DrawOptions{}
+	opts.GeoM.Translate(320, top*2/3)
+	text.Draw(screen, "Maze Game", face7x13, opts)
+
+	// Draw menu options
+	for i, option := range s.options {
+		y := top + float64(i)*spacing
+		opts := &text.DrawOptions{}
+		opts.GeoM.Translate(300, y)
+
+		menuText := option
+		switch option {
+		case "Daily":
+			menuText = option + ": " + time.Now().UTC().Format("2006-01-02")
+		case "Levels":
+			menuText = option + ": Random"
+			if s.level >= 0 {
+				choice := s.levels[s.level]
+				menuText = fmt.Sprintf("%s: %s %d/%d %s", option, choice.pack.Name,
+					choice.index+1, len(choice.pack.Levels), choice.pack.Levels[choice.index].Name)
+			}
+		case "Player Speed":
+			menuText = option + ": " + s.playerSpeed.String()
+		case "Maze Size":
+			menuText = option + ": " + s.mazeSize.String()
+		case "Shape":
+			menuText = option + ": " + s.mazeShape.String()
+		case "Grid":
+			menuText = option + ": " + s.grid.String()
+		case "Floors":
+			menuText = option + ": " + s.floors.String()
+		case "Algorithm":
+			menuText = option + ": " + s.algorithm.String()
+		case "Loops":
+			menuText = option + ": " + s.loopDensity.String()
+		case "Difficulty":
+			menuText = option + ": " + s.difficulty.String()
+		}
+
+		if i == s.selectedOption {
+			opts.ColorScale.Scale(1, 1, 0, 1) // Yellow
+			text.Draw(screen, "> "+menuText, face7x13, opts)
+		} else {
+			opts.ColorScale.Scale(1, 1, 1, 1) // White
+			text.Draw(screen, "  "+menuText, face7x13, opts)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/bfreis/ebitentools/ebitenwrap"
+	"github.com/bfreis/trijam-304/game"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type ScreenType int
+
+const (
+	ScreenTitle ScreenType = iota
+	ScreenMaze
+	ScreenAbout
+	ScreenLevelSelect
+	ScreenLevelComplete
+)
+
+type ScreenTransition struct {
+	NextScreen ScreenType
+	// For maze screen, we need to pass these parameters
+	PlayerSpeed game.PlayerSpeed
+	MazeSize    game.MazeSize
+	MazeShape   game.MazeShape
+	Grid        game.Grid
+	Floors      game.Floors
+	Seed        int64
+	Algorithm   game.MazeAlgorithm
+	LoopDensity game.LoopDensity
+	Difficulty  game.Difficulty
+	// Level, when set, is played instead of a generated maze, and Pack is
+	// the pack it belongs to
+	Level *game.Level
+	Pack  *game.LevelPack
+	// Campaign, when set, has the level at index CampaignLevel played
+	// instead of a generated maze
+	Campaign      *game.Campaign
+	CampaignLevel int
+	// Daily, when set, is the daily challenge to play instead of a
+	// generated maze, and Practice tells it was already completed, so the
+	// run does not count
+	Daily    *game.Daily
+	Practice bool
+}
+
+// NewMazeScreen starts the maze screen the transition asks for
+func (t *ScreenTransition) NewMazeScreen() (*MazeScreen, error) {
+	if t.Daily != nil {
+		screen, err := NewMazeScreen(t.Daily.Speed, t.Daily.MazeConfig())
+		if err != nil {
+			return nil, err
+		}
+		date := t.Daily.Date
+		if t.Practice {
+			date += " (Practice)"
+			screen.practice = true
+		}
+		screen.info = fmt.Sprintf("Daily challenge: %s  Speed: %s", date, t.Daily.Speed)
+		return screen, nil
+	}
+	if t.Campaign != nil {
+		level := t.Campaign.Levels[t.CampaignLevel]
+		screen, err := NewMazeScreen(level.Speed, level.MazeConfig())
+		if err != nil {
+			return nil, err
+		}
+		screen.info = fmt.Sprintf("Campaign %d/%d: %s", t.CampaignLevel+1, len(t.Campaign.Levels), level.Name)
+		return screen, nil
+	}
+	if t.Level == nil {
+		return NewMazeScreen(t.PlayerSpeed, t.MazeConfig())
+	}
+	name := fmt.Sprintf("%s: %s", t.Pack.Name, t.Level.Name)
+	if t.Level.Par > 0 {
+		name += fmt.Sprintf("  Par: %s", t.Level.Par)
+	}
+	return NewMazeScreenFromMaze(t.Level.Speed, t.Level.Maze, name)
+}
+
+// MazeConfig returns the configuration of the maze requested by the transition
+func (t *ScreenTransition) MazeConfig() game.MazeConfig {
+	width, height := t.MazeSize.Dimensions()
+	switch t.Grid {
+	case game.GridTriangle:
+		// Triangles are half as wide as squares
+		width *= 2
+	case game.GridPolar:
+		// Outer rings get wide quickly, so trade rings for sectors
+		width, height = width*2, max(2, height/2)
+	}
+	return game.MazeConfig{
+		Width:      width,
+		Height:     height,
+		Seed:       t.Seed,
+		Generator:  t.Algorithm.Generator(),
+		Braid:      t.LoopDensity.Fraction(),
+		Difficulty: t.Difficulty.Band(),
+		// Keep the start from landing right next to the exit
+		Placement:   game.PlacementMinDistance,
+		MinDistance: (width + height) / 2,
+		Mask:        t.MazeShape.Mask(width, height),
+		Grid:        t.Grid,
+		Floors:      t.Floors.Count(),
+	}
+}
+
+type Screen interface {
+	Update(tick ebitenwrap.Tick) (*ScreenTransition, error)
+	Draw(screen *ebiten.Image)
+}